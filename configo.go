@@ -3,36 +3,134 @@ package configo
 import (
 	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"sync"
+	"time"
 
-	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
-	"github.com/vsysa/configo/internal/parser/defaultValues"
-	"github.com/vsysa/configo/internal/parser/env"
 	"github.com/vsysa/configo/notifier"
 )
 
 const (
 	DefaultConfigPath = "./config.yml"
+	// DefaultProfileEnvVar is the env var that selects the active profile.
+	DefaultProfileEnvVar = "CONFIGO_PROFILE"
 )
 
+// waitForFileInterval is how often the initial load is retried with WithWaitForFile.
+const waitForFileInterval = 250 * time.Millisecond
+
+// DefaultDebounce is how long the manager waits for a burst of change events
+// to settle before reloading, see WithDebounce.
+const DefaultDebounce = 100 * time.Millisecond
+
 var (
 	ConfigParsingError error = errors.New("error parsing config struct")
+	// ManagerClosedError is returned by Reload after Close.
+	ManagerClosedError error = errors.New("config manager is closed")
 )
 
 type ConfigManager[T any] struct {
 	config *T
 
-	configFilePath string
+	// configFiles is the stack of config files, merged in order:
+	// values from later files override values from earlier ones.
+	configFiles []string
+	// configDirs are glob patterns of config fragments (e.g. conf.d/*.yml)
+	// merged on top of configFiles in lexical order.
+	configDirs []string
+	// configFormat is the format of the config files. If empty,
+	// it is detected from the file extension.
+	configFormat Format
+	// documents are non-file config documents (readers, byte slices, fs.FS files)
+	// that replace the config files. They are not watched.
+	documents []Source
+	// baseDocuments sit between the `default` tags and the config files,
+	// e.g. a defaults.yml embedded into the binary.
+	baseDocuments []Source
+
+	// profile is the active profile, e.g. "prod". Every config file is overlaid
+	// with its profile counterpart (config.yml -> config.prod.yml).
+	profile       string
+	profileEnvVar string
+
+	// optionalConfigFiles tolerates missing config files: the manager then
+	// starts from defaults and env vars, and picks the files up once they appear.
+	optionalConfigFiles bool
+	// waitForFile is how long NewConfigManager retries the initial load
+	// until the config file appears, parses and validates.
+	waitForFile time.Duration
+
+	// dotenvFiles are .env files forming an env layer below the real env vars.
+	// Missing files are skipped.
+	dotenvFiles []string
+
+	// flagSet is the parsed command-line flag set layered on top of env vars.
+	flagSet *flag.FlagSet
+	// flagArgs are command-line arguments to be parsed into flagSet.
+	flagArgs []string
+
+	// pollInterval switches file watching from fsnotify to polling.
+	pollInterval time.Duration
+
+	// reloadRequests coalesces the change notifications of all watchers;
+	// reloadLoop reloads once they have settled for debounce.
+	reloadRequests chan notifier.UpdateSource
+	debounce       time.Duration
+	// forceUpdates publishes reloads that did not change the config.
+	forceUpdates bool
+
+	// reloadSignals trigger a reload when received, e.g. SIGHUP.
+	reloadSignals []os.Signal
+
+	// sources are the layers of the configuration, in precedence order.
+	// Unless set with WithSources, they are built from the options above.
+	sources []Source
+
+	// strictInterpolation fails loading on ${VAR} references to unset env vars.
+	strictInterpolation bool
+	// literalBindKeys are the lowercased bind keys whose `file:` values in
+	// config documents are taken literally; fileRefsDisabled applies it to all.
+	literalBindKeys  map[string]struct{}
+	fileRefsDisabled bool
+
+	// values and layers are the merged values and the values of each source
+	// of the current config, kept to explain where a value came from.
+	values map[string]interface{}
+	layers []sourceLayer
+
+	// version counts the published configs, starting at 1 for the initial load.
+	version uint64
+
+	// secretFiles are the files referenced by `file:` values and _FILE env vars
+	// in the last loaded config. They are watched by watcher.
+	secretFiles []string
+	// watcher watches the files of all file sources and the secret files
+	// with a single fsnotify watcher. It is created by fileWatcher once there
+	// is a file to watch, and runs until watchCtx is done.
+	watcher   *fileWatcher
+	watchCtx  context.Context
+	watcherMu sync.Mutex
+
+	// stop cancels the context of the watchers, wg tracks their goroutines
+	// and done is closed once Close has been called.
+	stop      context.CancelFunc
+	done      <-chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
 
 	configUpdateNotifier *notifier.ConfigUpdateNotifier[T]
 	updateMu             sync.RWMutex
-	errorHandler         func(error)
-	v                    *viper.Viper
+	// reloadMu serializes reloads triggered by different sources.
+	reloadMu     sync.Mutex
+	errorHandler func(error)
 }
 
 func MustNewConfigManager[T any](opts ...Option[T]) *ConfigManager[T] {
@@ -45,31 +143,72 @@ func MustNewConfigManager[T any](opts ...Option[T]) *ConfigManager[T] {
 
 func NewConfigManager[T any](opts ...Option[T]) (*ConfigManager[T], error) {
 	r := &ConfigManager[T]{
-		configFilePath:       DefaultConfigPath,
-		configUpdateNotifier: notifier.NewConfigUpdateNotifier[T](),
+		configFiles:     []string{DefaultConfigPath},
+		profileEnvVar:   DefaultProfileEnvVar,
+		reloadRequests:  make(chan notifier.UpdateSource, 1),
+		debounce:        DefaultDebounce,
+		literalBindKeys: make(map[string]struct{}),
 		errorHandler: func(err error) {
 			log.Printf("ConfigManager error: %v", err)
 		},
-		v: viper.New(),
 	}
 
 	for _, opt := range opts {
 		opt(r)
 	}
 
-	err := r.setupViper(r.configFilePath)
-	if err != nil {
-		return nil, err
+	var notifierOpts []notifier.NotifierOption[T]
+	if r.forceUpdates {
+		notifierOpts = append(notifierOpts, notifier.WithForceUpdates[T]())
+	}
+	r.configUpdateNotifier = notifier.NewConfigUpdateNotifier[T](notifierOpts...)
+
+	if r.profileEnvVar != "" {
+		if profile, ok := os.LookupEnv(r.profileEnvVar); ok && profile != "" {
+			r.profile = profile
+		}
+	}
+
+	if r.sources == nil {
+		sources, err := r.defaultSources()
+		if err != nil {
+			return nil, err
+		}
+		r.sources = sources
 	}
-	r.setupWatcher()
 
-	if _, err := r.updateConfig(); err != nil {
+	if err := r.loadInitialConfig(context.Background()); err != nil {
 		return nil, err
 	}
 
+	ctx, stop := context.WithCancel(context.Background())
+	r.stop, r.done = stop, ctx.Done()
+	r.setupWatcher(ctx)
+
 	return r, nil
 }
 
+// Close stops watching the sources, waits for the internal goroutines to
+// finish and closes all ChangeCh channels. It is safe to call Close more
+// than once; the config remains readable afterwards.
+func (r *ConfigManager[T]) Close() error {
+	r.closeOnce.Do(func() {
+		r.stop()
+		r.wg.Wait()
+		r.configUpdateNotifier.Close()
+	})
+	return nil
+}
+
+// spawn runs fn in a goroutine that Close waits for.
+func (r *ConfigManager[T]) spawn(fn func()) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		fn()
+	}()
+}
+
 func (r *ConfigManager[T]) Config() T {
 	if r.config == nil {
 		panic("ConfigManager has not been initialized")
@@ -80,85 +219,472 @@ func (r *ConfigManager[T]) Config() T {
 	return *r.config
 }
 
+// Version returns the version of the current config: 1 after the initial
+// load, incremented by every reload that publishes a ConfigUpdateMsg.
+func (r *ConfigManager[T]) Version() uint64 {
+	r.updateMu.RLock()
+	defer r.updateMu.RUnlock()
+
+	return r.version
+}
+
+// Profile returns the active profile, or an empty string if none is selected.
+func (r *ConfigManager[T]) Profile() string {
+	return r.profile
+}
+
 func (r *ConfigManager[T]) ChangeCh(ctx context.Context) <-chan notifier.ConfigUpdateMsg[T] {
 	return r.configUpdateNotifier.Subscribe(ctx)
 }
 
-func (r *ConfigManager[T]) updateConfig() (*T, error) {
-	newConfig, err := r.loadConfig()
+// loadInitialConfig loads the config for the first time. With WithWaitForFile,
+// failed attempts are retried until one succeeds or the timeout elapses.
+func (r *ConfigManager[T]) loadInitialConfig(ctx context.Context) error {
+	_, err := r.updateConfig(ctx)
+	if err == nil || r.waitForFile <= 0 || errors.Is(err, ConfigParsingError) {
+		return err
+	}
+
+	deadline := time.NewTimer(r.waitForFile)
+	defer deadline.Stop()
+	ticker := time.NewTicker(waitForFileInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-deadline.C:
+			return fmt.Errorf("config is not ready after %s: %w", r.waitForFile, err)
+		case <-ticker.C:
+			if _, err = r.updateConfig(ctx); err == nil {
+				return nil
+			}
+		}
+	}
+}
+
+func (r *ConfigManager[T]) updateConfig(ctx context.Context) (*loadedConfig[T], error) {
+	loaded, err := r.loadConfig(ctx)
 	if err != nil {
 		return nil, err
 	}
 	r.updateMu.Lock()
-	r.config = newConfig
+	loaded.changed = r.config == nil || !reflect.DeepEqual(*r.config, *loaded.config)
+	if loaded.changed || r.forceUpdates {
+		r.version++
+	}
+	loaded.version, loaded.appliedAt = r.version, time.Now()
+	r.config = loaded.config
+	r.values = loaded.values
+	r.layers = loaded.layers
+	r.secretFiles = loaded.secretFiles
 	r.updateMu.Unlock()
-	return newConfig, nil
+
+	if len(loaded.secretFiles) > 0 {
+		if watcher := r.fileWatcher(); watcher != nil {
+			if err := watcher.Add(loaded.secretFiles...); err != nil {
+				r.errorHandler(err)
+			}
+		}
+	}
+	return loaded, nil
 }
 
-func (r *ConfigManager[T]) loadConfig() (*T, error) {
-	Viper := r.v
+// loadedConfig is the result of a successful load.
+type loadedConfig[T any] struct {
+	config *T
+	// values are the merged values the config was decoded from.
+	values map[string]interface{}
+	// layers are the values of each source, in precedence order.
+	layers []sourceLayer
+	// secretFiles are the files the config references, so that they can be watched.
+	secretFiles []string
+	// checksum is the hash of values.
+	checksum string
+	// changed, version and appliedAt are set by updateConfig once the config
+	// is applied; changed reports whether it differs from the previous one.
+	changed   bool
+	version   uint64
+	appliedAt time.Time
+}
+
+// loadConfig loads, merges and decodes all sources.
+func (r *ConfigManager[T]) loadConfig(ctx context.Context) (*loadedConfig[T], error) {
+	// Sources are deep-merged in precedence order: later sources win.
+	values := make(map[string]interface{})
+	layers := make([]sourceLayer, 0, len(r.sources))
+	for _, source := range r.sources {
+		sourceValues, locations, err := loadSource(ctx, source)
+		if err != nil {
+			return nil, err
+		}
+		layerValues := sourceValues
+		if isDocument(source) {
+			// The source values are kept as provided, to explain them later.
+			layerValues = copyValues(sourceValues)
+			// ${VAR} references are expanded in config documents only;
+			// env vars, dotenv files and flags are taken literally.
+			if err := interpolateValues(layerValues, r.strictInterpolation); err != nil {
+				err = fmt.Errorf("error interpolating %s: %w", sourceName(source), err)
+				return nil, maskSecrets(err, secretValues[T](sourceValues))
+			}
+			if !r.fileRefsDisabled {
+				markFileRefs(layerValues, "", r.literalBindKeys)
+			}
+		}
+		mergeValues(values, layerValues)
+		layers = append(layers, sourceLayer{source: source, values: sourceValues, locations: locations})
+	}
+
+	// File references are resolved after merging, so that a reference
+	// overridden by a higher layer is never read.
+	secretFiles, err := resolveFileRefs(values)
+	if err != nil {
+		return nil, err
+	}
+
+	// Errors from here on may quote values, so secret values are masked in them.
+	secrets := secretValues[T](values)
 
-	if err := Viper.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("error reading config file: %w", err)
+	// A fresh Viper instance is used for every load, so that keys removed
+	// from the files (or whole files removed from conf.d) do not linger.
+	Viper := viper.New()
+	if err := Viper.MergeConfigMap(values); err != nil {
+		return nil, maskSecrets(fmt.Errorf("error merging config: %w", err), secrets)
 	}
 
 	var cfg T
 	if err := Viper.Unmarshal(&cfg); err != nil {
-		return nil, fmt.Errorf("Unable to decode into struct: %v", err)
+		return nil, maskSecrets(fmt.Errorf("Unable to decode into struct: %v", err), secrets)
 	}
 
 	if err := callValidateIfExists(cfg); err != nil {
-		return nil, fmt.Errorf("Validation error: %w", err)
+		return nil, maskSecrets(fmt.Errorf("Validation error: %w", err), secrets)
 	}
 
-	return &cfg, nil
+	return &loadedConfig[T]{
+		config:      &cfg,
+		values:      values,
+		layers:      layers,
+		secretFiles: secretFiles,
+		checksum:    valuesChecksum(values),
+	}, nil
 }
 
-func (r *ConfigManager[T]) setupViper(configPath string) error {
-	Viper := r.v
+// isDocument reports whether the source reads config documents: config files,
+// config dirs and in-memory or fs.FS documents, as opposed to env vars, dotenv
+// files, flags and custom sources.
+func isDocument(source Source) bool {
+	switch source.(type) {
+	case *FileSource, *DirSource, rawDocument:
+		return true
+	default:
+		return false
+	}
+}
 
-	Viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	//Viper.AutomaticEnv()
+// defaultSources builds the source stack from the options, lowest precedence first:
+// `default` tags, embedded defaults, each config file followed by its profile
+// overlay, the non-file documents, the config dirs, dotenv files, env vars
+// and finally flags.
+func (r *ConfigManager[T]) defaultSources() ([]Source, error) {
+	if len(r.configFiles) == 0 && len(r.configDirs) == 0 && len(r.documents) == 0 {
+		return nil, errors.New("no config files specified")
+	}
 
-	Viper.SetConfigFile(configPath)
+	sources := []Source{&DefaultsSource[T]{profile: r.profile}}
+	sources = append(sources, r.baseDocuments...)
+	for _, path := range r.configFiles {
+		sources = append(sources, r.newFileSource(path, r.optionalConfigFiles))
+		if r.profile != "" {
+			sources = append(sources, r.newFileSource(profileFilePath(path, r.profile), true))
+		}
+	}
+	sources = append(sources, r.documents...)
+	for _, pattern := range r.configDirs {
+		sources = append(sources, NewDirSource(pattern))
+	}
+	for _, path := range r.dotenvFiles {
+		sources = append(sources, &DotenvSource[T]{path: path, optional: true})
+	}
+	sources = append(sources, NewEnvSource[T]())
 
-	var configStruct T
-	defaults, err := defaultValues.GetDefaultValues(configStruct)
-	if err != nil {
-		return fmt.Errorf("%w: %w", ConfigParsingError, err)
+	if r.flagArgs != nil {
+		r.flagSet = NewFlagSet[T](os.Args[0], flag.ContinueOnError)
+		if err := r.flagSet.Parse(r.flagArgs); err != nil {
+			return nil, fmt.Errorf("error parsing flags: %w", err)
+		}
 	}
-	for _, v := range defaults {
-		Viper.SetDefault(v.BindKey, v.DefaultValue)
+	if r.flagSet != nil {
+		sources = append(sources, NewFlagSource[T](r.flagSet))
 	}
 
-	for _, v := range env.GetEnvs(configStruct) {
-		err := Viper.BindEnv(v.BindKey, v.EnvVar)
-		if err != nil {
-			return fmt.Errorf("error binding env var: %w", err)
+	return sources, nil
+}
+
+// newFileSource creates the source of a config file. Dotenv files are mapped
+// through the env var names of T, any other format is decoded by Viper.
+func (r *ConfigManager[T]) newFileSource(path string, optional bool) Source {
+	format := r.configFormat
+	if format == "" {
+		format = formatFromPath(path)
+	}
+	if format == FormatDotenv {
+		return &DotenvSource[T]{path: path, optional: optional}
+	}
+	return &FileSource{path: path, format: format, optional: optional}
+}
+
+// addDocument adds a non-file config document, which replaces the config files.
+func (r *ConfigManager[T]) addDocument(source Source) {
+	r.configFiles = nil
+	r.documents = append(r.documents, source)
+}
+
+// setupWatcher watches every source that supports it
+// and reloads the merged configuration when any of them changes.
+func (r *ConfigManager[T]) setupWatcher(ctx context.Context) {
+	r.spawn(func() { r.reloadLoop(ctx) })
+
+	if r.pollInterval > 0 {
+		r.pollFiles(ctx)
+	} else {
+		r.watchFiles(ctx)
+	}
+	r.watchSignals(ctx)
+
+	for _, source := range r.sources {
+		if _, ok := source.(fileSource); ok {
+			continue // watched by watchFiles or polled by pollFiles
+		}
+		changes := source.Watch(ctx)
+		if changes == nil {
+			continue
+		}
+		r.spawn(func() {
+			// Sources should close the channel once ctx is done, but Close
+			// must not depend on it.
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case _, ok := <-changes:
+					if !ok {
+						return
+					}
+					r.requestReload(notifier.UpdateSourceWatch)
+				}
+			}
+		})
+	}
+}
+
+// pollFiles polls the files of the file sources and the secret files
+// instead of watching them with fsnotify.
+func (r *ConfigManager[T]) pollFiles(ctx context.Context) {
+	poller := newFilePoller(r.pollInterval, func() []string {
+		var files []string
+		for _, source := range r.sources {
+			if fs, ok := source.(fileSource); ok {
+				files = append(files, fs.watchedFiles()...)
+			}
+		}
+		r.updateMu.RLock()
+		files = append(files, r.secretFiles...)
+		r.updateMu.RUnlock()
+		return files
+	})
+
+	r.spawn(func() {
+		poller.Run(ctx, func() { r.requestReload(notifier.UpdateSourceFile) })
+	})
+}
+
+// watchFiles watches the files of the file sources and the secret files with
+// a single fileWatcher. Secret files referenced by later loads are added to
+// the watcher by updateConfig. Files that cannot be watched are reported to
+// the error handler; the manager keeps running without hot reload for them.
+func (r *ConfigManager[T]) watchFiles(ctx context.Context) {
+	r.watcherMu.Lock()
+	r.watchCtx = ctx
+	r.watcherMu.Unlock()
+
+	for _, source := range r.sources {
+		if fs, ok := source.(fileSource); ok {
+			w := r.fileWatcher()
+			if w == nil {
+				return
+			}
+			if err := fs.watch(w); err != nil {
+				r.errorHandler(err)
+			}
+		}
+	}
+
+	r.updateMu.RLock()
+	secretFiles := r.secretFiles
+	r.updateMu.RUnlock()
+
+	if len(secretFiles) > 0 {
+		if w := r.fileWatcher(); w != nil {
+			if err := w.Add(secretFiles...); err != nil {
+				r.errorHandler(err)
+			}
 		}
 	}
+}
+
+// fileWatcher returns the shared file watcher, creating and starting it on
+// first use. It returns nil before watchFiles has been called (and so in
+// polling mode), or if the watcher cannot be created.
+func (r *ConfigManager[T]) fileWatcher() *fileWatcher {
+	r.watcherMu.Lock()
+	defer r.watcherMu.Unlock()
+
+	if r.watcher != nil || r.watchCtx == nil {
+		return r.watcher
+	}
+	w, err := newFileWatcher()
+	if err != nil {
+		r.errorHandler(err)
+		return nil
+	}
+	w.errorHandler = r.errorHandler
+	r.watcher = w
+
+	ctx := r.watchCtx
+	r.spawn(func() { w.Run(ctx) })
+	r.spawn(func() {
+		for range w.changes {
+			r.requestReload(notifier.UpdateSourceFile)
+		}
+	})
+	return w
+}
 
+// Reload reloads the config from all sources right away and publishes a
+// ConfigUpdateMsg if the config has changed (or WithForceUpdates is set),
+// the same way a file change does. On error the current
+// config is kept and the error is returned instead of being passed to the
+// error handler.
+func (r *ConfigManager[T]) Reload(ctx context.Context) error {
+	select {
+	case <-r.done:
+		return ManagerClosedError
+	default:
+	}
+	if err := r.reload(ctx, notifier.UpdateSourceReload); err != nil {
+		if r.profile != "" {
+			return fmt.Errorf("Unable to reload config (profile %q): %w", r.profile, err)
+		}
+		return fmt.Errorf("Unable to reload config: %w", err)
+	}
 	return nil
 }
 
-func (r *ConfigManager[T]) setupWatcher() {
-	Viper := r.v
-	Viper.OnConfigChange(func(e fsnotify.Event) {
-		//fmt.Println("Config file changed:", e.Name)
-		oldConfig := r.Config()
-		newConfig, err := r.updateConfig()
-		if err != nil {
-			r.errorHandler(fmt.Errorf("Unable to load config on update: %v", err))
+// requestReload asks reloadLoop for a reload triggered by source. Requests
+// made while one is already pending are coalesced into it.
+func (r *ConfigManager[T]) requestReload(source notifier.UpdateSource) {
+	select {
+	case r.reloadRequests <- source:
+	default: // a reload is already pending
+	}
+}
+
+// reloadLoop reloads the config once per burst of reload requests: after a
+// request it waits until no further request has arrived for the debounce
+// window, so that a save producing several write events, or a half-written
+// file, results in a single reload.
+func (r *ConfigManager[T]) reloadLoop(ctx context.Context) {
+	for {
+		var source notifier.UpdateSource
+		select {
+		case <-ctx.Done():
 			return
+		case source = <-r.reloadRequests:
 		}
 
-		r.configUpdateNotifier.NewEvent(notifier.ConfigUpdateMsg[T]{
-			OldConfig: oldConfig,
-			NewConfig: *newConfig,
-		})
+		if r.debounce > 0 {
+			timer := time.NewTimer(r.debounce)
+		settle:
+			for {
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				case source = <-r.reloadRequests:
+					timer.Reset(r.debounce)
+				case <-timer.C:
+					break settle
+				}
+			}
+		}
+
+		r.onConfigChange(ctx, source)
+	}
+}
+
+func (r *ConfigManager[T]) onConfigChange(ctx context.Context, source notifier.UpdateSource) {
+	if err := r.reload(ctx, source); err != nil {
+		if r.profile != "" {
+			r.errorHandler(fmt.Errorf("Unable to load config on update (profile %q): %v", r.profile, err))
+		} else {
+			r.errorHandler(fmt.Errorf("Unable to load config on update: %v", err))
+		}
+	}
+}
+
+func (r *ConfigManager[T]) reload(ctx context.Context, source notifier.UpdateSource) error {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+
+	oldConfig := r.Config()
+	loaded, err := r.updateConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if !loaded.changed && !r.forceUpdates {
+		return nil
+	}
+
+	r.configUpdateNotifier.NewEvent(notifier.ConfigUpdateMsg[T]{
+		OldConfig: oldConfig,
+		NewConfig: *loaded.config,
+		Profile:   r.profile,
+		Changes:   notifier.Diff(oldConfig, *loaded.config),
+		Version:   loaded.version,
+		Timestamp: loaded.appliedAt,
+		Source:    source,
+		Checksum:  loaded.checksum,
+	})
+	return nil
+}
+
+// watchSignals reloads the config whenever one of the reload signals arrives.
+func (r *ConfigManager[T]) watchSignals(ctx context.Context) {
+	if len(r.reloadSignals) == 0 {
+		return
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, r.reloadSignals...)
+
+	r.spawn(func() {
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signals:
+				r.requestReload(notifier.UpdateSourceSignal)
+			}
+		}
 	})
+}
 
-	Viper.WatchConfig()
+// profileFilePath returns the profile overlay of the given config file,
+// e.g. config.yml -> config.prod.yml.
+func profileFilePath(path, profile string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + profile + ext
 }
 
 func callValidateIfExists(in interface{}) error {
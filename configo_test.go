@@ -1,10 +1,21 @@
 package configo
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
+	"runtime"
 	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/vsysa/configo/notifier"
 )
 
 type DatabaseConfig struct {
@@ -89,6 +100,7 @@ Enable: false
 	if err != nil {
 		t.Fatalf("Failed to load config: %v", err)
 	}
+	defer cm.Close()
 
 	config := cm.Config()
 
@@ -206,6 +218,7 @@ server:
 	if err != nil {
 		t.Fatalf("Failed to load config: %v", err)
 	}
+	defer cm.Close()
 
 	config := cm.Config()
 
@@ -227,3 +240,1313 @@ server:
 		t.Errorf("Expected Enable to be true, got %v", config.Enable)
 	}
 }
+
+// Проверка слияния нескольких файлов конфигурации: последующие файлы переопределяют предыдущие
+func TestConfigManager_LayeredConfigFiles(t *testing.T) {
+	baseContent := `
+appName: "base"
+database:
+  url: "postgres://base:5432/db"
+  username: "baseuser"
+server:
+  host: "basehost"
+  port: 8080
+`
+	prodContent := `
+appName: "prod"
+server:
+  port: 9090
+`
+	localContent := `
+database:
+  username: "localuser"
+`
+	basePath := createTempYAMLConfig(t, baseContent)
+	defer os.Remove(basePath)
+	prodPath := createTempYAMLConfig(t, prodContent)
+	defer os.Remove(prodPath)
+	localPath := createTempYAMLConfig(t, localContent)
+	defer os.Remove(localPath)
+
+	setEnv(t, "SERVER_HOST", "envhost")
+	defer unsetEnv(t, "SERVER_HOST")
+
+	cm, err := NewConfigManager[TestConfig](WithConfigFiles[TestConfig](basePath, prodPath, localPath))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+
+	config := cm.Config()
+
+	if config.AppName != "prod" {
+		t.Errorf("Expected AppName to be 'prod', got '%s'", config.AppName)
+	}
+	if config.Database.URL != "postgres://base:5432/db" {
+		t.Errorf("Expected Database.URL to be 'postgres://base:5432/db', got '%s'", config.Database.URL)
+	}
+	if config.Database.Username != "localuser" {
+		t.Errorf("Expected Database.Username to be 'localuser', got '%s'", config.Database.Username)
+	}
+	if config.Server.Port != 9090 {
+		t.Errorf("Expected Server.Port to be 9090, got %d", config.Server.Port)
+	}
+	// Переменные окружения по-прежнему имеют наивысший приоритет
+	if config.Server.Host != "envhost" {
+		t.Errorf("Expected Server.Host to be 'envhost', got '%s'", config.Server.Host)
+	}
+}
+
+// Проверка горячей перезагрузки при изменении любого файла из стека
+func TestConfigManager_LayeredConfigFilesReload(t *testing.T) {
+	basePath := createTempYAMLConfig(t, "appName: \"base\"\nserver:\n  port: 8080\n")
+	defer os.Remove(basePath)
+	overridePath := createTempYAMLConfig(t, "appName: \"override\"\n")
+	defer os.Remove(overridePath)
+
+	cm, err := NewConfigManager[TestConfig](WithConfigFiles[TestConfig](basePath, overridePath))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := cm.ChangeCh(ctx)
+
+	if err := os.WriteFile(overridePath, []byte("appName: \"changed\"\nserver:\n  port: 9090\n"), 0o644); err != nil {
+		t.Fatalf("Failed to update config file: %v", err)
+	}
+
+	update := waitForUpdate(t, updates, func(c TestConfig) bool { return c.AppName == "changed" })
+	if update.OldConfig.AppName != "override" {
+		t.Errorf("Expected OldConfig.AppName to be 'override', got '%s'", update.OldConfig.AppName)
+	}
+	if update.NewConfig.Server.Port != 9090 {
+		t.Errorf("Expected NewConfig.Server.Port to be 9090, got %d", update.NewConfig.Server.Port)
+	}
+}
+
+// waitForUpdate ждет сообщение об обновлении, удовлетворяющее условию
+func waitForUpdate[T any](t *testing.T, updates <-chan notifier.ConfigUpdateMsg[T], cond func(T) bool) notifier.ConfigUpdateMsg[T] {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case update := <-updates:
+			if cond(update.NewConfig) {
+				return update
+			}
+		case <-timeout:
+			t.Fatalf("Timeout waiting for config update")
+			return notifier.ConfigUpdateMsg[T]{}
+		}
+	}
+}
+
+// Проверка загрузки фрагментов из каталога conf.d в лексическом порядке
+func TestConfigManager_ConfigDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "20-server.yml"), "server:\n  port: 9090\n")
+	writeFile(t, filepath.Join(dir, "10-base.yml"), "appName: \"base\"\nserver:\n  host: \"basehost\"\n  port: 8080\n")
+	writeFile(t, filepath.Join(dir, "ignored.txt"), "appName: \"ignored\"\n")
+
+	cm, err := NewConfigManager[TestConfig](
+		WithConfigFiles[TestConfig](),
+		WithConfigDir[TestConfig](filepath.Join(dir, "*.yml")),
+	)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+
+	config := cm.Config()
+	if config.AppName != "base" {
+		t.Errorf("Expected AppName to be 'base', got '%s'", config.AppName)
+	}
+	if config.Server.Host != "basehost" {
+		t.Errorf("Expected Server.Host to be 'basehost', got '%s'", config.Server.Host)
+	}
+	if config.Server.Port != 9090 {
+		t.Errorf("Expected Server.Port to be 9090, got %d", config.Server.Port)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := cm.ChangeCh(ctx)
+
+	// Добавление нового фрагмента
+	writeFile(t, filepath.Join(dir, "30-app.yml"), "appName: \"added\"\n")
+	waitForUpdate(t, updates, func(c TestConfig) bool { return c.AppName == "added" })
+
+	// Удаление фрагмента
+	if err := os.Remove(filepath.Join(dir, "20-server.yml")); err != nil {
+		t.Fatalf("Failed to remove config fragment: %v", err)
+	}
+	waitForUpdate(t, updates, func(c TestConfig) bool { return c.Server.Port == 8080 })
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write file %s: %v", path, err)
+	}
+}
+
+type ProfileTestConfig struct {
+	AppName string `mapstructure:"appName"`
+	Port    int    `mapstructure:"port" default:"8080" default_prod:"80"`
+	Debug   bool   `mapstructure:"debug" default:"true" default_prod:"false"`
+}
+
+// Проверка наложения файла профиля (config.yml -> config.prod.yml)
+func TestConfigManager_Profile(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yml")
+	writeFile(t, configPath, "appName: \"base\"\n")
+	writeFile(t, filepath.Join(dir, "config.prod.yml"), "appName: \"prod\"\n")
+
+	cm, err := NewConfigManager[ProfileTestConfig](
+		WithConfigFilePath[ProfileTestConfig](configPath),
+		WithProfile[ProfileTestConfig]("prod"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+
+	config := cm.Config()
+	if cm.Profile() != "prod" {
+		t.Errorf("Expected profile to be 'prod', got '%s'", cm.Profile())
+	}
+	if config.AppName != "prod" {
+		t.Errorf("Expected AppName to be 'prod', got '%s'", config.AppName)
+	}
+	if config.Port != 80 {
+		t.Errorf("Expected Port to be 80, got %d", config.Port)
+	}
+	if config.Debug {
+		t.Errorf("Expected Debug to be false, got %v", config.Debug)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := cm.ChangeCh(ctx)
+
+	writeFile(t, filepath.Join(dir, "config.prod.yml"), "appName: \"prod2\"\n")
+	update := waitForUpdate(t, updates, func(c ProfileTestConfig) bool { return c.AppName == "prod2" })
+	if update.Profile != "prod" {
+		t.Errorf("Expected update profile to be 'prod', got '%s'", update.Profile)
+	}
+}
+
+// Проверка выбора профиля через переменную окружения
+func TestConfigManager_ProfileFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yml")
+	writeFile(t, configPath, "appName: \"base\"\n")
+	writeFile(t, filepath.Join(dir, "config.staging.yml"), "appName: \"staging\"\n")
+
+	setEnv(t, DefaultProfileEnvVar, "staging")
+	defer unsetEnv(t, DefaultProfileEnvVar)
+
+	cm, err := NewConfigManager[ProfileTestConfig](
+		WithConfigFilePath[ProfileTestConfig](configPath),
+		WithProfile[ProfileTestConfig]("prod"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+
+	config := cm.Config()
+	if config.AppName != "staging" {
+		t.Errorf("Expected AppName to be 'staging', got '%s'", config.AppName)
+	}
+	// Для профиля без default_staging используются обычные значения по умолчанию
+	if config.Port != 8080 {
+		t.Errorf("Expected Port to be 8080, got %d", config.Port)
+	}
+}
+
+// Проверка запуска без файла конфигурации и его подхвата после появления
+func TestConfigManager_OptionalConfigFile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+
+	setEnv(t, "APP", "envapp")
+	defer unsetEnv(t, "APP")
+
+	cm, err := NewConfigManager[TestConfig](
+		WithConfigFilePath[TestConfig](configPath),
+		WithOptionalConfigFile[TestConfig](),
+	)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+
+	config := cm.Config()
+	if config.AppName != "envapp" {
+		t.Errorf("Expected AppName to be 'envapp', got '%s'", config.AppName)
+	}
+	if config.Server.Port != 8081 {
+		t.Errorf("Expected Server.Port to be 8081, got %d", config.Server.Port)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := cm.ChangeCh(ctx)
+
+	writeFile(t, configPath, "server:\n  port: 9090\n")
+	update := waitForUpdate(t, updates, func(c TestConfig) bool { return c.Server.Port == 9090 })
+	if update.NewConfig.AppName != "envapp" {
+		t.Errorf("Expected NewConfig.AppName to be 'envapp', got '%s'", update.NewConfig.AppName)
+	}
+}
+
+// Удаление необязательного файла возвращает значения по умолчанию
+func TestConfigManager_OptionalConfigFileRemoved(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	writeFile(t, configPath, "server:\n  port: 9090\n")
+
+	cm, err := NewConfigManager[TestConfig](
+		WithConfigFilePath[TestConfig](configPath),
+		WithOptionalConfigFile[TestConfig](),
+	)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := cm.ChangeCh(ctx)
+
+	if err := os.Remove(configPath); err != nil {
+		t.Fatalf("Failed to remove config file: %v", err)
+	}
+	waitForUpdate(t, updates, func(c TestConfig) bool { return c.Server.Port == 8081 })
+}
+
+// Ошибки настройки отслеживания файлов передаются в обработчик ошибок
+func TestConfigManager_WatchSetupError(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "missing", "config.yml")
+
+	var mu sync.Mutex
+	var errs []error
+	cm, err := NewConfigManager[TestConfig](
+		WithConfigFilePath[TestConfig](configPath),
+		WithOptionalConfigFile[TestConfig](),
+		WithErrorHandler[TestConfig](func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, err)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(errs) == 0 {
+		t.Errorf("Expected an error for the unwatchable config directory")
+	}
+}
+
+// Без опции отсутствующий файл по-прежнему является ошибкой
+func TestConfigManager_MissingConfigFile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+
+	if _, err := NewConfigManager[TestConfig](WithConfigFilePath[TestConfig](configPath)); err == nil {
+		t.Errorf("Expected error for missing config file")
+	}
+}
+
+// Проверка ожидания появления файла конфигурации при старте
+func TestConfigManager_WaitForFile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+
+	go func() {
+		time.Sleep(500 * time.Millisecond)
+		// Файл появляется атомарно, как при монтировании тома
+		writeFile(t, configPath+".tmp", "appName: \"mounted\"\n")
+		if err := os.Rename(configPath+".tmp", configPath); err != nil {
+			t.Errorf("Failed to rename config file: %v", err)
+		}
+	}()
+
+	cm, err := NewConfigManager[TestConfig](
+		WithConfigFilePath[TestConfig](configPath),
+		WithWaitForFile[TestConfig](5*time.Second),
+	)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+
+	if config := cm.Config(); config.AppName != "mounted" {
+		t.Errorf("Expected AppName to be 'mounted', got '%s'", config.AppName)
+	}
+}
+
+// Проверка ошибки по истечении времени ожидания
+func TestConfigManager_WaitForFileTimeout(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+
+	_, err := NewConfigManager[TestConfig](
+		WithConfigFilePath[TestConfig](configPath),
+		WithWaitForFile[TestConfig](300*time.Millisecond),
+	)
+	if err == nil {
+		t.Errorf("Expected error when config file does not appear in time")
+	}
+}
+
+// Проверка встроенного документа со значениями по умолчанию:
+// он переопределяет теги default, но уступает файлу и переменным окружения
+func TestConfigManager_EmbeddedDefaults(t *testing.T) {
+	embedded := fstest.MapFS{
+		"defaults.yml": &fstest.MapFile{Data: []byte("appName: \"embedded\"\nserver:\n  host: \"embeddedhost\"\n  port: 7070\n")},
+	}
+	configPath := createTempYAMLConfig(t, "server:\n  port: 8080\n")
+	defer os.Remove(configPath)
+
+	setEnv(t, "SERVER_HOST", "envhost")
+	defer unsetEnv(t, "SERVER_HOST")
+
+	cm, err := NewConfigManager[TestConfig](
+		WithConfigFilePath[TestConfig](configPath),
+		WithEmbeddedDefaults[TestConfig](embedded, "defaults.yml"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+
+	config := cm.Config()
+	if config.AppName != "embedded" {
+		t.Errorf("Expected AppName to be 'embedded', got '%s'", config.AppName)
+	}
+	if config.Server.Port != 8080 {
+		t.Errorf("Expected Server.Port to be 8080, got %d", config.Server.Port)
+	}
+	if config.Server.Host != "envhost" {
+		t.Errorf("Expected Server.Host to be 'envhost', got '%s'", config.Server.Host)
+	}
+	if !config.Enable {
+		t.Errorf("Expected Enable to be true, got %v", config.Enable)
+	}
+
+	template, err := GenerateYAMLTemplateFromFS(ServerConfig{}, false, fstest.MapFS{
+		"defaults.yml": &fstest.MapFile{Data: []byte("port: 7070\n")},
+	}, "defaults.yml")
+	if err != nil {
+		t.Fatalf("Failed to generate template: %v", err)
+	}
+	if expected := "host: \"defaulthost\"\nport: 7070\n"; template != expected {
+		t.Errorf("Expected template %q, got %q", expected, template)
+	}
+}
+
+// Проверка загрузки файлов JSON, TOML и dotenv, сгенерированных по шаблонам
+func TestConfigManager_FileFormats(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		content  string
+		opts     []Option[TestConfig]
+	}{
+		{name: "json", fileName: "config.json", content: GenerateJSONTemplate(TestConfig{})},
+		{name: "toml", fileName: "config.toml", content: GenerateTOMLTemplate(TestConfig{}, true)},
+		{name: "dotenv", fileName: ".env", content: GenerateDotenvTemplate(TestConfig{}, true)},
+		{
+			name:     "explicit format",
+			fileName: "config",
+			content:  GenerateTOMLTemplate(TestConfig{}, false),
+			opts:     []Option[TestConfig]{WithConfigFormat[TestConfig](FormatTOML)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), tt.fileName)
+			writeFile(t, configPath, strings.Replace(tt.content, "8081", "9090", 1))
+
+			opts := append([]Option[TestConfig]{WithConfigFilePath[TestConfig](configPath)}, tt.opts...)
+			cm, err := NewConfigManager[TestConfig](opts...)
+			if err != nil {
+				t.Fatalf("Failed to load config: %v\n%s", err, tt.content)
+			}
+			defer cm.Close()
+
+			config := cm.Config()
+			if config.Server.Host != "defaulthost" {
+				t.Errorf("Expected Server.Host to be 'defaulthost', got '%s'", config.Server.Host)
+			}
+			if config.Server.Port != 9090 {
+				t.Errorf("Expected Server.Port to be 9090, got %d", config.Server.Port)
+			}
+			expectedStatuses := []string{"a", "b", "c", "aa", "ab"}
+			if !reflect.DeepEqual(config.Statuses, expectedStatuses) {
+				t.Errorf("Expected Statuses to be %v, got %v", expectedStatuses, config.Statuses)
+			}
+			if !config.Enable {
+				t.Errorf("Expected Enable to be true, got %v", config.Enable)
+			}
+		})
+	}
+}
+
+// Шаблон dotenv с map и срезом структур загружается без ошибок
+func TestConfigManager_DotenvTemplateCompositeFields(t *testing.T) {
+	type Item struct {
+		Name string `mapstructure:"name"`
+	}
+	type Config struct {
+		Labels map[string]string `mapstructure:"labels" default:"{\"env\":\"prod\"}"`
+		Items  []Item            `mapstructure:"items"`
+		Name   string            `mapstructure:"name" default:"app"`
+	}
+
+	configPath := filepath.Join(t.TempDir(), ".env")
+	writeFile(t, configPath, GenerateDotenvTemplate(Config{}, true))
+
+	cm, err := NewConfigManager[Config](WithConfigFilePath[Config](configPath))
+	if err != nil {
+		t.Fatalf("Failed to load dotenv template: %v", err)
+	}
+	defer cm.Close()
+
+	if labels := cm.Config().Labels; labels["env"] != "prod" {
+		t.Errorf("Expected the default labels, got %v", labels)
+	}
+}
+
+// Тестирование флагов командной строки: флаги перекрывают env и файл
+func TestConfigManager_Flags(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	writeFile(t, configPath, "appName: fileApp\nserver:\n  host: filehost\n  port: 9090\n")
+
+	setEnv(t, "APP", "envApp")
+	defer unsetEnv(t, "APP")
+
+	cm, err := NewConfigManager[TestConfig](
+		WithConfigFilePath[TestConfig](configPath),
+		WithFlags[TestConfig]([]string{"--appName=flagApp", "-server.port", "7070", "--enable=false"}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+
+	config := cm.Config()
+	if config.AppName != "flagApp" {
+		t.Errorf("Expected AppName to be 'flagApp', got '%s'", config.AppName)
+	}
+	if config.Server.Host != "filehost" {
+		t.Errorf("Expected Server.Host to be 'filehost', got '%s'", config.Server.Host)
+	}
+	if config.Server.Port != 7070 {
+		t.Errorf("Expected Server.Port to be 7070, got %d", config.Server.Port)
+	}
+	if config.Enable {
+		t.Errorf("Expected Enable to be false, got %v", config.Enable)
+	}
+
+	_, err = NewConfigManager[TestConfig](
+		WithConfigFilePath[TestConfig](configPath),
+		WithFlags[TestConfig]([]string{"--unknown=1"}),
+	)
+	if err == nil {
+		t.Errorf("Expected an error for an unknown flag")
+	}
+}
+
+// Проверка .env файлов: они ниже реальных env и перечитываются при изменении
+func TestConfigManager_DotenvFiles(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yml")
+	writeFile(t, configPath, "appName: fileApp\nserver:\n  port: 9090\n")
+	dotenvPath := filepath.Join(dir, ".env")
+	writeFile(t, dotenvPath, "export APP=\"dotenv app\"\nSERVER_HOST=dotenvhost\nDB_PASSWORD=\"multi\nline\"\n")
+
+	setEnv(t, "SERVER_HOST", "envhost")
+	defer unsetEnv(t, "SERVER_HOST")
+
+	cm, err := NewConfigManager[TestConfig](
+		WithConfigFilePath[TestConfig](configPath),
+		WithDotenvFiles[TestConfig](dotenvPath, filepath.Join(dir, ".env.local")),
+	)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+
+	config := cm.Config()
+	if config.AppName != "dotenv app" {
+		t.Errorf("Expected AppName to be 'dotenv app', got '%s'", config.AppName)
+	}
+	if config.Server.Host != "envhost" {
+		t.Errorf("Expected Server.Host to be 'envhost', got '%s'", config.Server.Host)
+	}
+	if config.Server.Port != 9090 {
+		t.Errorf("Expected Server.Port to be 9090, got %d", config.Server.Port)
+	}
+	if config.Database.Password != "multi\nline" {
+		t.Errorf("Expected Database.Password to be 'multi\\nline', got '%s'", config.Database.Password)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := cm.ChangeCh(ctx)
+
+	writeFile(t, filepath.Join(dir, ".env.local"), "APP=local\n")
+
+	update := waitForUpdate(t, updates, func(c TestConfig) bool { return c.AppName == "local" })
+	if update.OldConfig.AppName != "dotenv app" {
+		t.Errorf("Expected OldConfig.AppName to be 'dotenv app', got '%s'", update.OldConfig.AppName)
+	}
+}
+
+// Проверка секретов из файлов: _FILE env и ссылки file: в YAML, ротация секрета
+func TestConfigManager_SecretFiles(t *testing.T) {
+	dir := t.TempDir()
+	passwordPath := filepath.Join(dir, "db_password")
+	writeFile(t, passwordPath, "s3cret\n")
+	urlPath := filepath.Join(dir, "db_url")
+	writeFile(t, urlPath, "postgres://db\n")
+
+	configPath := filepath.Join(dir, "config.yml")
+	writeFile(t, configPath, "database:\n  url: file:"+urlPath+"\n  username: user\n")
+
+	setEnv(t, "DB_PASSWORD_FILE", passwordPath)
+	defer unsetEnv(t, "DB_PASSWORD_FILE")
+
+	cm, err := NewConfigManager[TestConfig](
+		WithConfigFilePath[TestConfig](configPath),
+	)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+
+	config := cm.Config()
+	if config.Database.Password != "s3cret" {
+		t.Errorf("Expected Database.Password to be 's3cret', got '%s'", config.Database.Password)
+	}
+	if config.Database.URL != "postgres://db" {
+		t.Errorf("Expected Database.URL to be 'postgres://db', got '%s'", config.Database.URL)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := cm.ChangeCh(ctx)
+
+	writeFile(t, passwordPath, "rotated\n")
+
+	update := waitForUpdate(t, updates, func(c TestConfig) bool { return c.Database.Password == "rotated" })
+	if update.OldConfig.Database.Password != "s3cret" {
+		t.Errorf("Expected OldConfig.Database.Password to be 's3cret', got '%s'", update.OldConfig.Database.Password)
+	}
+
+	writeFile(t, configPath, "database:\n  url: file:"+filepath.Join(dir, "missing")+"\n")
+	_, err = NewConfigManager[TestConfig](
+		WithConfigFilePath[TestConfig](configPath),
+	)
+	if err == nil {
+		t.Errorf("Expected an error for a missing secret file")
+	}
+}
+
+// WithoutFileRefs оставляет значения "file:" как есть, например SQLite DSN
+func TestConfigManager_WithoutFileRefs(t *testing.T) {
+	dir := t.TempDir()
+	passwordPath := filepath.Join(dir, "db_password")
+	writeFile(t, passwordPath, "s3cret\n")
+
+	configPath := filepath.Join(dir, "config.yml")
+	writeFile(t, configPath, "database:\n  url: \"file:test.db?cache=shared\"\n  password: file:"+passwordPath+"\n")
+
+	// Без опции DSN читается как путь к файлу, которого нет
+	if _, err := NewConfigManager[TestConfig](WithConfigFilePath[TestConfig](configPath)); err == nil {
+		t.Errorf("Expected the file: DSN to be read as a missing file")
+	}
+
+	cm, err := NewConfigManager[TestConfig](
+		WithConfigFilePath[TestConfig](configPath),
+		WithoutFileRefs[TestConfig]("Database.URL"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to load config with a file: DSN: %v", err)
+	}
+	defer cm.Close()
+	if config := cm.Config(); config.Database.URL != "file:test.db?cache=shared" || config.Database.Password != "s3cret" {
+		t.Errorf("Expected only the DSN to be kept, got %+v", config.Database)
+	}
+
+	disabled, err := NewConfigManager[TestConfig](
+		WithConfigFilePath[TestConfig](configPath),
+		WithoutFileRefs[TestConfig](),
+	)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer disabled.Close()
+	if config := disabled.Config(); config.Database.Password != "file:"+passwordPath {
+		t.Errorf("Expected file: values to be kept, got %+v", config.Database)
+	}
+
+	secretPath := filepath.Join(dir, "secret.yml")
+	writeFile(t, secretPath, "database:\n  password: file:"+passwordPath+"\n")
+	setEnv(t, "TOKEN", "file:"+passwordPath)
+	defer unsetEnv(t, "TOKEN")
+
+	secrets, err := NewConfigManager[SecretConfig](WithConfigFilePath[SecretConfig](secretPath))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer secrets.Close()
+	config := secrets.Config()
+	if config.Database.Password != "s3cret" {
+		t.Errorf("Expected the secret field to be read from the file, got %q", config.Database.Password)
+	}
+	// Переменные окружения не читают файлы через "file:", только через _FILE
+	if config.Token.Value() != "file:"+passwordPath {
+		t.Errorf("Expected the env value to be kept, got %q", config.Token.Value())
+	}
+}
+
+// Проверка подстановки ${VAR} в значениях YAML и строгого режима
+func TestConfigManager_Interpolation(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	writeFile(t, configPath, "database:\n  url: \"postgres://${CONFIGO_DB_HOST:-localhost}:5432/app\"\n  username: \"${CONFIGO_DB_USER}\"\nserver:\n  port: ${CONFIGO_PORT:-9090}\n")
+
+	setEnv(t, "CONFIGO_DB_HOST", "dbhost")
+	defer unsetEnv(t, "CONFIGO_DB_HOST")
+
+	cm, err := NewConfigManager[TestConfig](WithConfigFilePath[TestConfig](configPath))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+
+	config := cm.Config()
+	if config.Database.URL != "postgres://dbhost:5432/app" {
+		t.Errorf("Expected Database.URL to be 'postgres://dbhost:5432/app', got '%s'", config.Database.URL)
+	}
+	if config.Database.Username != "" {
+		t.Errorf("Expected Database.Username to be empty, got '%s'", config.Database.Username)
+	}
+	if config.Server.Port != 9090 {
+		t.Errorf("Expected Server.Port to be 9090, got %d", config.Server.Port)
+	}
+
+	_, err = NewConfigManager[TestConfig](
+		WithConfigFilePath[TestConfig](configPath),
+		WithStrictInterpolation[TestConfig](),
+	)
+	if !errors.Is(err, UndefinedVariableError) {
+		t.Errorf("Expected UndefinedVariableError, got %v", err)
+	}
+
+	help, err := GenerateEnvHelpFromFiles(TestConfig{}, Inline, configPath)
+	if err != nil {
+		t.Fatalf("Failed to generate env help: %v", err)
+	}
+	if !strings.Contains(help, "CONFIGO_DB_HOST [default=localhost]") || !strings.Contains(help, "CONFIGO_PORT [default=9090]") {
+		t.Errorf("Expected env help to list the referenced variables, got:\n%s", help)
+	}
+
+	// Переменные из включаемых файлов тоже попадают в справку
+	includedPath := filepath.Join(filepath.Dir(configPath), "included.yml")
+	writeFile(t, includedPath, "appName: \"${CONFIGO_INCLUDED_APP:-app}\"\n")
+	mainPath := filepath.Join(filepath.Dir(configPath), "main.yml")
+	writeFile(t, mainPath, "include: included.yml\n")
+	help, err = GenerateEnvHelpFromFiles(TestConfig{}, Inline, mainPath)
+	if err != nil {
+		t.Fatalf("Failed to generate env help: %v", err)
+	}
+	if !strings.Contains(help, "CONFIGO_INCLUDED_APP [default=app]") {
+		t.Errorf("Expected env help to list the variables of included files, got:\n%s", help)
+	}
+}
+
+// Значения env и флагов не интерполируются, подставляются только значения файлов
+func TestConfigManager_InterpolationFileLayerOnly(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	writeFile(t, configPath, "appName: \"${CONFIGO_APP}\"\n")
+
+	setEnv(t, "CONFIGO_APP", "expanded")
+	defer unsetEnv(t, "CONFIGO_APP")
+	setEnv(t, "DB_PASSWORD", "abc${CONFIGO_APP}")
+	defer unsetEnv(t, "DB_PASSWORD")
+	setEnv(t, "DB_USERNAME", "s3cr${et")
+	defer unsetEnv(t, "DB_USERNAME")
+
+	cm, err := NewConfigManager[TestConfig](
+		WithConfigFilePath[TestConfig](configPath),
+		WithFlags[TestConfig]([]string{"--server.host=${CONFIGO_APP}"}),
+		WithStrictInterpolation[TestConfig](),
+	)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+
+	config := cm.Config()
+	if config.AppName != "expanded" {
+		t.Errorf("Expected AppName to be 'expanded', got '%s'", config.AppName)
+	}
+	if config.Database.Password != "abc${CONFIGO_APP}" || config.Database.Username != "s3cr${et" {
+		t.Errorf("Expected env values to be taken literally, got %+v", config.Database)
+	}
+	if config.Server.Host != "${CONFIGO_APP}" {
+		t.Errorf("Expected the flag value to be taken literally, got '%s'", config.Server.Host)
+	}
+}
+
+// Проверка include: пути относительно файла, перечитывание включённых файлов, циклы
+func TestConfigManager_Include(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yml")
+	writeFile(t, configPath, "include:\n  - parts/server.yml\nappName: main\nserver:\n  host: mainhost\n")
+	serverPath := filepath.Join(dir, "parts", "server.yml")
+	if err := os.MkdirAll(filepath.Dir(serverPath), 0o755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	writeFile(t, serverPath, "include: database.yml\nserver:\n  host: parthost\n  port: 9090\n")
+	databasePath := filepath.Join(dir, "parts", "database.yml")
+	writeFile(t, databasePath, "database:\n  username: partuser\n")
+
+	cm, err := NewConfigManager[TestConfig](WithConfigFilePath[TestConfig](configPath))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+
+	config := cm.Config()
+	if config.AppName != "main" {
+		t.Errorf("Expected AppName to be 'main', got '%s'", config.AppName)
+	}
+	if config.Server.Host != "mainhost" {
+		t.Errorf("Expected Server.Host to be 'mainhost', got '%s'", config.Server.Host)
+	}
+	if config.Server.Port != 9090 {
+		t.Errorf("Expected Server.Port to be 9090, got %d", config.Server.Port)
+	}
+	if config.Database.Username != "partuser" {
+		t.Errorf("Expected Database.Username to be 'partuser', got '%s'", config.Database.Username)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := cm.ChangeCh(ctx)
+
+	writeFile(t, databasePath, "database:\n  username: changed\n")
+	waitForUpdate(t, updates, func(c TestConfig) bool { return c.Database.Username == "changed" })
+
+	writeFile(t, databasePath, "include: ../config.yml\n")
+	_, err = NewConfigManager[TestConfig](WithConfigFilePath[TestConfig](configPath))
+	if err == nil || !strings.Contains(err.Error(), "include cycle") {
+		t.Errorf("Expected an include cycle error, got %v", err)
+	}
+}
+
+// Пустой список include не попадает в значения конфигурации
+func TestReadConfigFileWithIncludes_EmptyInclude(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	writeFile(t, configPath, "include: []\nappName: app\n")
+
+	values, _, included, err := readConfigFileWithIncludes(configPath, "")
+	if err != nil {
+		t.Fatalf("Failed to read config file: %v", err)
+	}
+	if _, ok := values[IncludeKey]; ok || len(included) != 0 {
+		t.Errorf("Expected the empty include key to be dropped, got %v", values)
+	}
+	if values["appname"] != "app" {
+		t.Errorf("Expected appName to be read, got %v", values)
+	}
+}
+
+// Проверка происхождения значений: файл со строкой, env, default, флаги
+func TestConfigManager_Explain(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	writeFile(t, configPath, "appName: fileApp\nserver:\n  host: filehost\n  port: 9090\n")
+
+	setEnv(t, "SERVER_PORT", "7070")
+	defer unsetEnv(t, "SERVER_PORT")
+
+	cm, err := NewConfigManager[TestConfig](
+		WithConfigFilePath[TestConfig](configPath),
+		WithFlags[TestConfig]([]string{"-appName=flagApp"}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+
+	port, ok := cm.Provenance("server.port")
+	if !ok {
+		t.Fatalf("Expected provenance of server.port")
+	}
+	if port.Origin.Source != "env" || port.Origin.Location != "SERVER_PORT" || port.Value != "7070" {
+		t.Errorf("Expected server.port to come from SERVER_PORT, got %+v", port)
+	}
+	expectedOverridden := []Origin{
+		{Source: "file " + configPath, Location: configPath + ":4", Value: 9090},
+		{Source: "defaults", Location: "default tag", Value: int64(8081)},
+	}
+	if !reflect.DeepEqual(port.Overridden, expectedOverridden) {
+		t.Errorf("Expected server.port to override %+v, got %+v", expectedOverridden, port.Overridden)
+	}
+
+	appName, _ := cm.Provenance("appName")
+	if appName.Origin.Location != "-appName" {
+		t.Errorf("Expected appName to come from -appName, got %+v", appName.Origin)
+	}
+
+	if _, ok := cm.Provenance("database.username"); ok {
+		t.Errorf("Expected database.username to be unset")
+	}
+
+	explained := make(map[string]Provenance)
+	for _, p := range cm.Explain() {
+		explained[p.BindKey] = p
+	}
+	if host := explained["server.host"]; host.Origin.Location != configPath+":3" {
+		t.Errorf("Expected server.host to come from %s:3, got %+v", configPath, host.Origin)
+	}
+	if _, ok := explained["database.url"]; !ok {
+		t.Errorf("Expected Explain to list database.url")
+	}
+
+	// После неудачной перезагрузки описывается действующая конфигурация, а не файл на диске
+	writeFile(t, configPath, "server:\n  host: [broken\n")
+	if err := cm.Reload(context.Background()); err == nil {
+		t.Fatalf("Expected the reload of a broken file to fail")
+	}
+	if host, _ := cm.Provenance("server.host"); host.Origin.Location != configPath+":3" || host.Origin.Value != "filehost" {
+		t.Errorf("Expected server.host to still come from %s:3, got %+v", configPath, host.Origin)
+	}
+}
+
+// Проверка выгрузки действующей конфигурации с маскированием
+func TestConfigManager_Dump(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	writeFile(t, configPath, "appName: fileApp\ndatabase:\n  username: user\n  password: s3cret\nserver:\n  port: 9090\n")
+
+	cm, err := NewConfigManager[TestConfig](WithConfigFilePath[TestConfig](configPath))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+
+	for _, format := range []Format{FormatYAML, FormatJSON} {
+		out, err := cm.Dump(format, MaskKeys("*.password"))
+		if err != nil {
+			t.Fatalf("Failed to dump config as %s: %v", format, err)
+		}
+		if strings.Contains(out, "s3cret") || !strings.Contains(out, MaskedValue) {
+			t.Errorf("Expected the password to be masked in the %s dump, got:\n%s", format, out)
+		}
+
+		// Выгрузка читается обратно как файл конфигурации
+		dumpPath := filepath.Join(t.TempDir(), "dump."+string(format))
+		writeFile(t, dumpPath, out)
+		reloaded, err := NewConfigManager[TestConfig](WithConfigFilePath[TestConfig](dumpPath))
+		if err != nil {
+			t.Fatalf("Failed to load %s dump: %v\n%s", format, err, out)
+		}
+		defer reloaded.Close()
+		expected := cm.Config()
+		expected.Database.Password = MaskedValue
+		if !reflect.DeepEqual(reloaded.Config(), expected) {
+			t.Errorf("Expected the %s dump to load as %+v, got %+v", format, expected, reloaded.Config())
+		}
+	}
+
+	out, err := cm.Dump(FormatDotenv)
+	if err != nil {
+		t.Fatalf("Failed to dump config as env: %v", err)
+	}
+	for _, line := range []string{"APP=fileApp\n", "DB_PASSWORD=s3cret\n", "SERVER_PORT=9090\n", "STATUSES=a,b,c,aa,ab\n"} {
+		if !strings.Contains(out, line) {
+			t.Errorf("Expected the env dump to contain %q, got:\n%s", line, out)
+		}
+	}
+
+	if _, err := cm.Dump(FormatTOML); err == nil {
+		t.Errorf("Expected an error for an unsupported dump format")
+	}
+}
+
+type SecretConfig struct {
+	Database struct {
+		User     string `mapstructure:"user"`
+		Password string `mapstructure:"password" secret:"true" default:"changeme"`
+	} `mapstructure:"database"`
+	Token Secret `mapstructure:"token"`
+	Port  int    `mapstructure:"port"`
+}
+
+func (c SecretConfig) Validate() error {
+	if c.Port < 0 {
+		return fmt.Errorf("port %d is invalid for password %s", c.Port, c.Database.Password)
+	}
+	return nil
+}
+
+// Проверка маскирования секретов во всех выводах конфигурации
+func TestConfigManager_SecretFields(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	writeFile(t, configPath, "database:\n  user: user\n  password: s3cret\ntoken: t0ken\nport: 8080\n")
+
+	var reloadErr error
+	var errMu sync.Mutex
+	cm, err := NewConfigManager[SecretConfig](
+		WithConfigFilePath[SecretConfig](configPath),
+		WithErrorHandler[SecretConfig](func(err error) {
+			errMu.Lock()
+			reloadErr = err
+			errMu.Unlock()
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+
+	config := cm.Config()
+	if config.Database.Password != "s3cret" || config.Token.Value() != "t0ken" {
+		t.Fatalf("Expected the actual secret values, got %q and %q", config.Database.Password, config.Token.Value())
+	}
+
+	outputs := map[string]string{
+		"printed config":  fmt.Sprintf("%+v", Redact(config)),
+		"printed token":   fmt.Sprintf("%+v", config.Token),
+		"update message":  notifier.ConfigUpdateMsg[SecretConfig]{OldConfig: config, NewConfig: config}.String(),
+		"env help":        GenerateEnvHelp(SecretConfig{}, Inline),
+		"flag help":       GenerateFlagHelp(SecretConfig{}),
+		"yaml template":   GenerateYAMLTemplate(SecretConfig{}, true),
+		"json template":   GenerateJSONTemplate(SecretConfig{}),
+		"toml template":   GenerateTOMLTemplate(SecretConfig{}, true),
+		"dotenv template": GenerateDotenvTemplate(SecretConfig{}, true),
+	}
+	for _, format := range []Format{FormatYAML, FormatJSON, FormatDotenv} {
+		out, err := cm.Dump(format)
+		if err != nil {
+			t.Fatalf("Failed to dump config as %s: %v", format, err)
+		}
+		outputs["dump "+string(format)] = out
+	}
+	for _, p := range cm.Explain() {
+		outputs["provenance "+p.BindKey] = fmt.Sprintf("%+v", p)
+	}
+
+	for name, out := range outputs {
+		for _, leaked := range []string{"s3cret", "t0ken", "changeme"} {
+			if strings.Contains(out, leaked) {
+				t.Errorf("Expected %s not to contain %q, got:\n%s", name, leaked, out)
+			}
+		}
+	}
+
+	// Ошибка перезагрузки не должна содержать значение секрета
+	writeFile(t, configPath, "database:\n  password: s3cret\nport: -1\n")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		errMu.Lock()
+		err := reloadErr
+		errMu.Unlock()
+		if err != nil {
+			if strings.Contains(err.Error(), "s3cret") || !strings.Contains(err.Error(), MaskedValue) {
+				t.Errorf("Expected the secret to be masked in the reload error, got: %v", err)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timeout waiting for the reload error")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Ошибка интерполяции также не должна содержать значение секрета
+	writeFile(t, configPath, "database:\n  password: \"s3cr${et\"\nport: 8080\n")
+	_, err = NewConfigManager[SecretConfig](WithConfigFilePath[SecretConfig](configPath))
+	if err == nil || strings.Contains(err.Error(), "s3cr") {
+		t.Errorf("Expected an interpolation error without the secret, got: %v", err)
+	}
+}
+
+// Проверка ручной перезагрузки: env не отслеживаются, Reload их подхватывает
+func TestConfigManager_Reload(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	writeFile(t, configPath, "appName: fileApp\n")
+
+	cm, err := NewConfigManager[TestConfig](WithConfigFilePath[TestConfig](configPath))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := cm.ChangeCh(ctx)
+
+	setEnv(t, "APP", "envApp")
+	defer unsetEnv(t, "APP")
+
+	if err := cm.Reload(ctx); err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	if config := cm.Config(); config.AppName != "envApp" {
+		t.Errorf("Expected AppName to be 'envApp', got '%s'", config.AppName)
+	}
+	update := waitForUpdate(t, updates, func(c TestConfig) bool { return c.AppName == "envApp" })
+	if update.OldConfig.AppName != "fileApp" {
+		t.Errorf("Expected OldConfig.AppName to be 'fileApp', got '%s'", update.OldConfig.AppName)
+	}
+
+	// Ошибка возвращается, текущая конфигурация сохраняется
+	setEnv(t, "SERVER_PORT", "not-a-number")
+	defer unsetEnv(t, "SERVER_PORT")
+	if err := cm.Reload(ctx); err == nil {
+		t.Errorf("Expected an error for an invalid port")
+	}
+	if config := cm.Config(); config.AppName != "envApp" {
+		t.Errorf("Expected AppName to stay 'envApp', got '%s'", config.AppName)
+	}
+}
+
+// Проверка списка изменённых полей в сообщении об обновлении
+func TestConfigManager_Changes(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	writeFile(t, configPath, "appName: app\nserver:\n  port: 8080\n")
+
+	cm, err := NewConfigManager[TestConfig](WithConfigFilePath[TestConfig](configPath))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := cm.ChangeCh(ctx)
+
+	writeFile(t, configPath, "appName: app\nserver:\n  port: 9090\nstatuses: [x]\n")
+	if err := cm.Reload(ctx); err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	update := waitForUpdate(t, updates, func(c TestConfig) bool { return c.Server.Port == 9090 })
+
+	expected := []notifier.Change{
+		{Path: "server.port", Old: 8080, New: 9090},
+		{Path: "statuses", Old: []string{"a", "b", "c", "aa", "ab"}, New: []string{"x"}},
+	}
+	if !reflect.DeepEqual(update.Changes, expected) {
+		t.Errorf("Expected changes %v, got %v", expected, update.Changes)
+	}
+	if !update.Changed("server.port") || !update.Changed("server") {
+		t.Errorf("Expected server.port to be reported as changed")
+	}
+	if update.Changed("appName") {
+		t.Errorf("Expected appName not to be reported as changed")
+	}
+}
+
+// Проверка метаданных обновления: версия, время, причина и контрольная сумма
+func TestConfigManager_UpdateMetadata(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	writeFile(t, configPath, "appName: v1\n")
+
+	cm, err := NewConfigManager[TestConfig](WithConfigFilePath[TestConfig](configPath))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+	if cm.Version() != 1 {
+		t.Errorf("Expected Version to be 1 after the initial load, got %d", cm.Version())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := cm.ChangeCh(ctx)
+
+	setEnv(t, "SERVER_PORT", "9090")
+	defer unsetEnv(t, "SERVER_PORT")
+
+	before := time.Now()
+	if err := cm.Reload(ctx); err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	reloaded := waitForUpdate(t, updates, func(c TestConfig) bool { return c.Server.Port == 9090 })
+	if reloaded.Version != 2 || cm.Version() != 2 {
+		t.Errorf("Expected Version 2, got %d (manager %d)", reloaded.Version, cm.Version())
+	}
+	if reloaded.Source != notifier.UpdateSourceReload {
+		t.Errorf("Expected Source to be %q, got %q", notifier.UpdateSourceReload, reloaded.Source)
+	}
+	if reloaded.Timestamp.Before(before) {
+		t.Errorf("Expected Timestamp after %v, got %v", before, reloaded.Timestamp)
+	}
+	if len(reloaded.Checksum) != 64 {
+		t.Errorf("Expected a SHA-256 checksum, got %q", reloaded.Checksum)
+	}
+
+	writeFile(t, configPath, "appName: v2\n")
+	changed := waitForUpdate(t, updates, func(c TestConfig) bool { return c.AppName == "v2" })
+	if changed.Version <= reloaded.Version {
+		t.Errorf("Expected Version to grow past %d, got %d", reloaded.Version, changed.Version)
+	}
+	if changed.Source != notifier.UpdateSourceFile {
+		t.Errorf("Expected Source to be %q, got %q", notifier.UpdateSourceFile, changed.Source)
+	}
+	if changed.Checksum == reloaded.Checksum {
+		t.Errorf("Expected the checksum to change with the content")
+	}
+}
+
+// Проверка пропуска перезагрузок, не изменивших конфигурацию
+func TestConfigManager_SkipUnchanged(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	writeFile(t, configPath, "appName: app\n")
+
+	cm, err := NewConfigManager[TestConfig](WithConfigFilePath[TestConfig](configPath))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := cm.ChangeCh(ctx)
+
+	// Файл перезаписан тем же содержимым, а перезагрузка ничего не меняет
+	writeFile(t, configPath, "appName: app\n")
+	if err := cm.Reload(ctx); err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	select {
+	case update := <-updates:
+		t.Errorf("Expected no update for an unchanged config, got %v", update)
+	case <-time.After(500 * time.Millisecond):
+	}
+	if cm.Version() != 1 {
+		t.Errorf("Expected Version to stay 1, got %d", cm.Version())
+	}
+
+	writeFile(t, configPath, "appName: changed\n")
+	waitForUpdate(t, updates, func(c TestConfig) bool { return c.AppName == "changed" })
+}
+
+// Проверка принудительной публикации неизменившейся конфигурации
+func TestConfigManager_ForceUpdates(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	writeFile(t, configPath, "appName: app\n")
+
+	cm, err := NewConfigManager[TestConfig](
+		WithConfigFilePath[TestConfig](configPath),
+		WithForceUpdates[TestConfig](),
+	)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := cm.ChangeCh(ctx)
+
+	if err := cm.Reload(ctx); err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	update := waitForUpdate(t, updates, func(c TestConfig) bool { return c.AppName == "app" })
+	if len(update.Changes) != 0 {
+		t.Errorf("Expected no changes, got %v", update.Changes)
+	}
+	if update.Version != 2 {
+		t.Errorf("Expected Version 2, got %d", update.Version)
+	}
+}
+
+// Проверка подавления дребезга: серия записей даёт одну перезагрузку
+func TestConfigManager_Debounce(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	writeFile(t, configPath, "appName: v0\n")
+
+	cm, err := NewConfigManager[TestConfig](
+		WithConfigFilePath[TestConfig](configPath),
+		WithDebounce[TestConfig](200*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := cm.ChangeCh(ctx)
+
+	for i := 1; i <= 5; i++ {
+		writeFile(t, configPath, fmt.Sprintf("appName: v%d\n", i))
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	update := waitForUpdate(t, updates, func(TestConfig) bool { return true })
+	if update.OldConfig.AppName != "v0" || update.NewConfig.AppName != "v5" {
+		t.Errorf("Expected a single update from v0 to v5, got %s -> %s", update.OldConfig.AppName, update.NewConfig.AppName)
+	}
+	select {
+	case update := <-updates:
+		t.Errorf("Expected a single update, got another one to %s", update.NewConfig.AppName)
+	case <-time.After(500 * time.Millisecond):
+	}
+}
+
+// Проверка Close: каналы подписчиков закрываются, горутины завершаются
+func TestConfigManager_Close(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	writeFile(t, configPath, "appName: fileApp\n")
+
+	before := runtime.NumGoroutine()
+
+	cm, err := NewConfigManager[TestConfig](
+		WithConfigFilePath[TestConfig](configPath),
+		WithSources[TestConfig](NewFileSource(configPath), NewMemorySource(nil)),
+	)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	updates := cm.ChangeCh(context.Background())
+
+	if err := cm.Close(); err != nil {
+		t.Fatalf("Failed to close config manager: %v", err)
+	}
+	if _, ok := <-updates; ok {
+		t.Errorf("Expected the ChangeCh channel to be closed")
+	}
+	if _, ok := <-cm.ChangeCh(context.Background()); ok {
+		t.Errorf("Expected ChangeCh to return a closed channel after Close")
+	}
+	if err := cm.Reload(context.Background()); !errors.Is(err, ManagerClosedError) {
+		t.Errorf("Expected ManagerClosedError, got %v", err)
+	}
+	if config := cm.Config(); config.AppName != "fileApp" {
+		t.Errorf("Expected the config to stay readable, got '%s'", config.AppName)
+	}
+	if err := cm.Close(); err != nil {
+		t.Errorf("Expected a repeated Close to succeed, got %v", err)
+	}
+
+	// Горутины наблюдателей завершаются вместе с менеджером
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected goroutines to finish, %d are still running (%d before)", runtime.NumGoroutine(), before)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
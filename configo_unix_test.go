@@ -0,0 +1,98 @@
+//go:build unix
+
+package configo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/vsysa/configo/notifier"
+)
+
+// Проверка перезагрузки по сигналу SIGHUP
+func TestConfigManager_ReloadOnSignal(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	writeFile(t, configPath, "appName: fileApp\n")
+
+	cm, err := NewConfigManager[TestConfig](
+		WithConfigFilePath[TestConfig](configPath),
+		WithReloadOnSignal[TestConfig](syscall.SIGHUP),
+	)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := cm.ChangeCh(ctx)
+
+	setEnv(t, "APP", "signalApp")
+	defer unsetEnv(t, "APP")
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatalf("Failed to send SIGHUP: %v", err)
+	}
+
+	update := waitForUpdate(t, updates, func(c TestConfig) bool { return c.AppName == "signalApp" })
+	if update.Source != notifier.UpdateSourceSignal {
+		t.Errorf("Expected Source to be %q, got %q", notifier.UpdateSourceSignal, update.Source)
+	}
+}
+
+// Проверка опроса файлов: подмена симлинка ..data как в ConfigMap Kubernetes
+func TestConfigManager_PollConfigMapSwap(t *testing.T) {
+	dir := t.TempDir()
+	for _, version := range []string{"v1", "v2", "v3"} {
+		if err := os.Mkdir(filepath.Join(dir, version), 0o755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+	}
+	writeFile(t, filepath.Join(dir, "v1", "config.yml"), "appName: v1\n")
+	if err := os.Symlink("v1", filepath.Join(dir, "..data")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	if err := os.Symlink(filepath.Join("..data", "config.yml"), filepath.Join(dir, "config.yml")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	cm, err := NewConfigManager[TestConfig](
+		WithConfigFilePath[TestConfig](filepath.Join(dir, "config.yml")),
+		WithPollInterval[TestConfig](20*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := cm.ChangeCh(ctx)
+
+	// Новая версия с тем же содержимым не должна вызывать перезагрузку
+	swap := func(version, content string) {
+		writeFile(t, filepath.Join(dir, version, "config.yml"), content)
+		if err := os.Symlink(version, filepath.Join(dir, "..data_tmp")); err != nil {
+			t.Fatalf("Failed to create symlink: %v", err)
+		}
+		if err := os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")); err != nil {
+			t.Fatalf("Failed to swap symlink: %v", err)
+		}
+	}
+	swap("v2", "appName: v1\n")
+	select {
+	case update := <-updates:
+		t.Errorf("Expected no reload for unchanged content, got %+v", update.NewConfig)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	swap("v3", "appName: v3\n")
+	update := waitForUpdate(t, updates, func(c TestConfig) bool { return c.AppName == "v3" })
+	if update.OldConfig.AppName != "v1" {
+		t.Errorf("Expected OldConfig.AppName to be 'v1', got '%s'", update.OldConfig.AppName)
+	}
+}
@@ -0,0 +1,75 @@
+package configo
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/vsysa/configo/internal/parser/dump"
+	"gopkg.in/yaml.v3"
+)
+
+// MaskedValue replaces secret and masked values in printed configs,
+// the output of Dump and the generated help.
+const MaskedValue = dump.MaskedValue
+
+// DumpOption configures Dump.
+type DumpOption func(*dumpOptions)
+
+type dumpOptions struct {
+	maskPatterns []string
+}
+
+// MaskKeys masks the values of bind keys matching any of the given glob
+// patterns (see path.Match), compared case-insensitively, e.g.
+// "database.password" or "*.token".
+func MaskKeys(patterns ...string) DumpOption {
+	return func(o *dumpOptions) {
+		o.maskPatterns = append(o.maskPatterns, patterns...)
+	}
+}
+
+// Dump serializes the current config snapshot in the given format: YAML, JSON,
+// or dotenv, i.e. KEY=value lines named like the env vars of T. Keys are named
+// the way the config is read, so a YAML or JSON dump can be loaded back.
+// Secret fields are always masked.
+func (r *ConfigManager[T]) Dump(format Format, opts ...DumpOption) (string, error) {
+	var o dumpOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	config := r.Config()
+	values := dump.ToMap(config, o.masked)
+
+	switch format {
+	case FormatYAML:
+		out, err := yaml.Marshal(values)
+		if err != nil {
+			return "", fmt.Errorf("error dumping config as %s: %w", format, err)
+		}
+		return string(out), nil
+	case FormatJSON:
+		out, err := json.MarshalIndent(values, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("error dumping config as %s: %w", format, err)
+		}
+		return string(out) + "\n", nil
+	case FormatDotenv:
+		return dump.EnvLines(config, values), nil
+	default:
+		return "", fmt.Errorf("unsupported dump format %q", format)
+	}
+}
+
+// masked reports whether the value of bindKey is to be masked.
+func (o *dumpOptions) masked(bindKey string) bool {
+	bindKey = strings.ToLower(bindKey)
+	for _, pattern := range o.maskPatterns {
+		if matched, _ := path.Match(strings.ToLower(pattern), bindKey); matched {
+			return true
+		}
+	}
+	return false
+}
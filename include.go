@@ -0,0 +1,135 @@
+package configo
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// IncludeKey is the top-level key listing the files a config file includes:
+//
+//	include:
+//	  - database.yml
+//	  - logging.yml
+//
+// Included files are resolved relative to the including file and merged
+// below it, so the including file overrides what it includes.
+const IncludeKey = "include"
+
+// readConfigFileWithIncludes reads a config file and, recursively, the files it
+// includes. It also returns the location of each key (see fileLocations) and
+// the paths of all included files.
+func readConfigFileWithIncludes(path string, format Format) (map[string]interface{}, map[string]string, []string, error) {
+	var included []string
+	values, locations, err := readIncludedFile(path, format, nil, &included)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return values, locations, included, nil
+}
+
+func readIncludedFile(path string, format Format, stack []string, included *[]string) (map[string]interface{}, map[string]string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error resolving config file %s: %w", path, err)
+	}
+	for _, parent := range stack {
+		if parent == absPath {
+			return nil, nil, fmt.Errorf("include cycle: %s -> %s", strings.Join(stack, " -> "), absPath)
+		}
+	}
+	stack = append(stack, absPath)
+
+	fileValues, fileLocations, err := readLocatedConfigFile(path, format)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	includes, err := includePaths(fileValues[IncludeKey])
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading config file %s: %w", path, err)
+	}
+	// The key is dropped even if it lists no files, e.g. `include: []`,
+	// so that it is never decoded into a config field.
+	delete(fileValues, IncludeKey)
+	delete(fileLocations, IncludeKey)
+	if len(includes) == 0 {
+		return fileValues, fileLocations, nil
+	}
+
+	values := make(map[string]interface{})
+	locations := make(map[string]string)
+	for _, include := range includes {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+		*included = append(*included, include)
+
+		includedValues, includedLocations, err := readIncludedFile(include, "", stack, included)
+		if err != nil {
+			return nil, nil, err
+		}
+		mergeValues(values, includedValues)
+		maps.Copy(locations, includedLocations)
+	}
+	mergeValues(values, fileValues)
+	maps.Copy(locations, fileLocations)
+	return values, locations, nil
+}
+
+// includePaths returns the paths of the `include` key, which is either
+// a single path or a list of paths.
+func includePaths(include interface{}) ([]string, error) {
+	switch v := include.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		paths := make([]string, 0, len(v))
+		for _, item := range v {
+			path, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: expected a file path, got %v", IncludeKey, item)
+			}
+			paths = append(paths, path)
+		}
+		return paths, nil
+	default:
+		return nil, fmt.Errorf("%s: expected a file path or a list of file paths, got %v", IncludeKey, include)
+	}
+}
+
+// includeWatch adds the files included by a source to the source's watcher,
+// since they are only known once the source has been loaded.
+type includeWatch struct {
+	mu       sync.Mutex
+	included []string
+	watcher  *fileWatcher
+}
+
+// track records the included files of the last load and starts watching them.
+// Errors are reported to the error handler of the watcher, since the load
+// itself has succeeded.
+func (iw *includeWatch) track(included []string) {
+	iw.mu.Lock()
+	defer iw.mu.Unlock()
+
+	iw.included = included
+	if iw.watcher != nil {
+		if err := iw.watcher.Add(included...); err != nil {
+			iw.watcher.reportError(err)
+		}
+	}
+}
+
+// watch registers the watcher and adds the files included so far.
+func (iw *includeWatch) watch(w *fileWatcher) error {
+	iw.mu.Lock()
+	defer iw.mu.Unlock()
+
+	iw.watcher = w
+	return w.Add(iw.included...)
+}
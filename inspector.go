@@ -1,10 +1,17 @@
 package configo
 
 import (
+	"context"
+	"flag"
 	"fmt"
+	"io/fs"
 	"strings"
 
+	"github.com/vsysa/configo/internal/parser/dotenv"
 	"github.com/vsysa/configo/internal/parser/env"
+	"github.com/vsysa/configo/internal/parser/flags"
+	"github.com/vsysa/configo/internal/parser/json"
+	"github.com/vsysa/configo/internal/parser/toml"
 	"github.com/vsysa/configo/internal/parser/yaml"
 
 	"unicode/utf8"
@@ -14,6 +21,46 @@ func GenerateYAMLTemplate(cfg interface{}, printDescription bool) string {
 	return yaml.GenerateYAMLTemplate(cfg, printDescription)
 }
 
+// GenerateProfileYAMLTemplates generates one YAML template per profile, keyed by
+// profile name. Each template uses the profile's `default_<profile>` tags where
+// present and falls back to the `default` tags otherwise.
+func GenerateProfileYAMLTemplates(cfg interface{}, printDescription bool, profiles ...string) map[string]string {
+	templates := make(map[string]string, len(profiles))
+	for _, profile := range profiles {
+		templates[profile] = yaml.GenerateProfileYAMLTemplate(cfg, printDescription, profile)
+	}
+	return templates
+}
+
+// GenerateJSONTemplate generates an indented JSON template with the default values
+// of the struct. JSON has no comments, so help texts are not included.
+func GenerateJSONTemplate(cfg interface{}) string {
+	return json.GenerateJSONTemplate(cfg)
+}
+
+// GenerateTOMLTemplate generates a TOML template with the default values of the
+// struct, optionally with help texts as comments.
+func GenerateTOMLTemplate(cfg interface{}, printDescription bool) string {
+	return toml.GenerateTOMLTemplate(cfg, printDescription)
+}
+
+// GenerateDotenvTemplate generates a dotenv template with one VAR=default line
+// per environment variable, optionally with help texts as comments.
+func GenerateDotenvTemplate(cfg interface{}, printDescription bool) string {
+	return dotenv.GenerateDotenvTemplate(cfg, printDescription)
+}
+
+// GenerateYAMLTemplateFromFS generates a YAML template whose default values are
+// taken from the config document at path in fsys (e.g. the defaults.yml passed to
+// WithEmbeddedDefaults), falling back to the `default` tags for keys it does not set.
+func GenerateYAMLTemplateFromFS(cfg interface{}, printDescription bool, fsys fs.FS, path string) (string, error) {
+	values, err := NewFSSource(fsys, path).Load(context.Background())
+	if err != nil {
+		return "", err
+	}
+	return yaml.GenerateYAMLTemplateWithValues(cfg, printDescription, values), nil
+}
+
 // EnvHelpFormat defines the type of output format for environment variable docs.
 type EnvHelpFormat int
 
@@ -37,7 +84,17 @@ const (
 // For example, if a parent struct has `env:"db"` and the nested struct has a field
 // with `env:"host"`, it will generate `DB_HOST`.
 func GenerateEnvHelp(cfg interface{}, format EnvHelpFormat) string {
-	lines := env.GetEnvs(cfg)
+	return formatEnvHelp(env.GetEnvs(cfg), format)
+}
+
+// formatEnvHelp renders the env var docs in the given format.
+// Defaults of secret variables are masked.
+func formatEnvHelp(lines []env.EnvInfo, format EnvHelpFormat) string {
+	for i, info := range lines {
+		if info.Secret && info.DefaultValue != "" {
+			lines[i].DefaultValue = MaskedValue
+		}
+	}
 
 	// Choose the output format based on the 'format' parameter
 	switch format {
@@ -52,6 +109,56 @@ func GenerateEnvHelp(cfg interface{}, format EnvHelpFormat) string {
 	}
 }
 
+// GenerateEnvHelpFromFiles is like GenerateEnvHelp, but also lists the env vars
+// referenced as ${VAR} by the values of the given config files and the files
+// they include, along with their `:-` defaults and the keys referencing them.
+func GenerateEnvHelpFromFiles(cfg interface{}, format EnvHelpFormat, paths ...string) (string, error) {
+	lines := env.GetEnvs(cfg)
+	known := make(map[string]struct{}, len(lines))
+	for _, info := range lines {
+		known[info.EnvVar] = struct{}{}
+	}
+
+	for _, path := range paths {
+		values, locations, _, err := readConfigFileWithIncludes(path, "")
+		if err != nil {
+			return "", err
+		}
+		for _, ref := range collectEnvRefs(values) {
+			if _, ok := known[ref.Name]; ok {
+				continue
+			}
+			known[ref.Name] = struct{}{}
+			location, ok := locations[strings.ToLower(ref.BindKey)]
+			if !ok {
+				location = path
+			}
+			lines = append(lines, env.EnvInfo{
+				EnvVar:       ref.Name,
+				DefaultValue: ref.Default,
+				HelpText:     fmt.Sprintf("Referenced by %s in %s", ref.BindKey, location),
+				BindKey:      ref.BindKey,
+				ValueType:    "string",
+			})
+		}
+	}
+
+	return formatEnvHelp(lines, format), nil
+}
+
+// GenerateFlagHelp returns the usage of the command-line flags generated
+// from cfg (see NewFlagSet), in the format printed by the flag package:
+//
+//	-server.port string
+//	  	Server port (default "8080")
+func GenerateFlagHelp(cfg interface{}) string {
+	var sb strings.Builder
+	flagSet := flags.NewFlagSet(cfg, "", flag.ContinueOnError)
+	flagSet.SetOutput(&sb)
+	flagSet.PrintDefaults()
+	return sb.String()
+}
+
 // formatEnvHelpInline displays each environment variable on a single line.
 // Example:
 //
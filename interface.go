@@ -2,6 +2,7 @@ package configo
 
 import (
 	"context"
+	"io"
 
 	"github.com/vsysa/configo/notifier"
 )
@@ -14,4 +15,8 @@ type IConfigManager[T any] interface {
 
 	// ChangeCh возвращает канал, по которому можно получать сообщения об изменении конфигурации.
 	ChangeCh(ctx context.Context) <-chan notifier.ConfigUpdateMsg[T]
+
+	// Close останавливает отслеживание изменений, дожидается завершения
+	// внутренних горутин и закрывает все каналы ChangeCh.
+	io.Closer
 }
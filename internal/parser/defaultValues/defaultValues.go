@@ -15,12 +15,18 @@ type DefaultInfo struct {
 }
 
 func GetDefaultValues(cfg interface{}) ([]DefaultInfo, error) {
+	return GetProfileDefaultValues(cfg, "")
+}
+
+// GetProfileDefaultValues works like GetDefaultValues, but prefers
+// profile-specific `default_<profile>:"..."` tags over the `default` tag.
+func GetProfileDefaultValues(cfg interface{}, profile string) ([]DefaultInfo, error) {
 	var lines []DefaultInfo
-	err := parseDefaultValues(reflect.TypeOf(cfg), "", &lines)
+	err := parseDefaultValues(reflect.TypeOf(cfg), "", profile, &lines)
 	return lines, err
 }
 
-func parseDefaultValues(t reflect.Type, parentBindKey string, lines *[]DefaultInfo) error {
+func parseDefaultValues(t reflect.Type, parentBindKey, profile string, lines *[]DefaultInfo) error {
 	// If the type is a pointer, unwrap it to its element type.
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
@@ -53,14 +59,14 @@ func parseDefaultValues(t reflect.Type, parentBindKey string, lines *[]DefaultIn
 		// We assume *non*-map, non-slice struct fields can have nested env variables.
 		if fieldKind == reflect.Struct {
 			// Recurse into nested struct.
-			err := parseDefaultValues(field.Type, childBindKey, lines)
+			err := parseDefaultValues(field.Type, childBindKey, profile, lines)
 			if err != nil {
 				return err
 			}
 			continue
 		}
 
-		defaultValStr := getDefaultValue(field.Tag)
+		defaultValStr := getDefaultValue(field.Tag, profile)
 		if defaultValStr == "" {
 			continue
 		}
@@ -159,8 +165,13 @@ func getMapstructureKey(field reflect.StructField) string {
 }
 
 // getDefaultValue extracts the default value from struct tags.
-// It first checks the "default" tag, then falls back to "placeholder".
-func getDefaultValue(tag reflect.StructTag) string {
+// It first checks the profile-specific "default_<profile>" tag, then falls back to "default".
+func getDefaultValue(tag reflect.StructTag, profile string) string {
+	if profile != "" {
+		if profileVal, ok := tag.Lookup("default_" + profile); ok {
+			return profileVal
+		}
+	}
 	defaultVal := tag.Get("default")
 	return defaultVal
 }
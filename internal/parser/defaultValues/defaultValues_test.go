@@ -75,3 +75,23 @@ func TestGetDefaultValues_NoDefaults(t *testing.T) {
 
 	assert.Len(t, defaults, 0, "no defaults should be added if not specified")
 }
+
+func TestGetProfileDefaultValues(t *testing.T) {
+	type Config struct {
+		Host  string `mapstructure:"host" default:"localhost" default_prod:"0.0.0.0"`
+		Port  int    `mapstructure:"port" default:"8080" default_staging:"8081"`
+		Debug bool   `mapstructure:"debug" default:"true" default_prod:"false"`
+	}
+
+	cfg := Config{}
+	defaults, err := GetProfileDefaultValues(cfg, "prod")
+	require.NoError(t, err)
+
+	expected := []DefaultInfo{
+		{BindKey: "host", DefaultValue: "0.0.0.0"},
+		{BindKey: "port", DefaultValue: int64(8080)},
+		{BindKey: "debug", DefaultValue: false},
+	}
+
+	assert.EqualValues(t, expected, defaults)
+}
@@ -0,0 +1,84 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/vsysa/configo/internal/parser/secret"
+)
+
+// Change is a changed field of the config:
+//   - Path: the bind key of the field, e.g. "server.port".
+//   - Old, New: the values before and after the change. Secret values are masked.
+type Change struct {
+	Path string
+	Old  interface{}
+	New  interface{}
+}
+
+// Diff compares two configs of the same struct type field by field and returns
+// the changed fields in struct order. Nested structs are compared recursively,
+// any other field (including slices and maps) as a whole.
+func Diff(oldCfg, newCfg interface{}) []Change {
+	oldVal, newVal := reflect.ValueOf(oldCfg), reflect.ValueOf(newCfg)
+	if !oldVal.IsValid() || !newVal.IsValid() || oldVal.Type() != newVal.Type() {
+		return nil
+	}
+	var changes []Change
+	diffValues(oldVal, newVal, "", &changes)
+	return changes
+}
+
+func diffValues(oldVal, newVal reflect.Value, parentBindKey string, changes *[]Change) {
+	if oldVal.Kind() == reflect.Ptr {
+		if oldVal.IsNil() || newVal.IsNil() {
+			if oldVal.IsNil() != newVal.IsNil() {
+				*changes = append(*changes, Change{Path: parentBindKey, Old: oldVal.Interface(), New: newVal.Interface()})
+			}
+			return
+		}
+		oldVal, newVal = oldVal.Elem(), newVal.Elem()
+	}
+	if oldVal.Kind() != reflect.Struct {
+		return
+	}
+
+	t := oldVal.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		// Skip unexported and ignored fields.
+		if field.PkgPath != "" || field.Tag.Get("mapstructure") == "-" {
+			continue
+		}
+
+		bindKey := getFieldName(field)
+		if parentBindKey != "" {
+			bindKey = parentBindKey + "." + bindKey
+		}
+
+		oldField, newField := oldVal.Field(i), newVal.Field(i)
+		if field.Type.Kind() == reflect.Struct && !secret.IsSecretField(field) {
+			diffValues(oldField, newField, bindKey, changes)
+			continue
+		}
+		if reflect.DeepEqual(oldField.Interface(), newField.Interface()) {
+			continue
+		}
+
+		change := Change{Path: bindKey, Old: oldField.Interface(), New: newField.Interface()}
+		if secret.IsSecretField(field) {
+			change.Old, change.New = secret.MaskedValue, secret.MaskedValue
+		}
+		*changes = append(*changes, change)
+	}
+}
+
+// getFieldName returns the key of the field as Viper decodes it,
+// the same way env.GetEnvs builds bind keys: the mapstructure tag or the lowercase field name.
+func getFieldName(field reflect.StructField) string {
+	if key := field.Tag.Get("mapstructure"); key != "" {
+		return key
+	}
+	return strings.ToLower(field.Name)
+}
@@ -0,0 +1,47 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vsysa/configo/internal/parser/secret"
+)
+
+type server struct {
+	Host string `mapstructure:"host"`
+	Port int
+}
+
+type config struct {
+	Server   server   `mapstructure:"server"`
+	Tags     []string `mapstructure:"tags"`
+	Password string   `mapstructure:"password" secret:"true"`
+	Ignored  string   `mapstructure:"-"`
+	internal string
+}
+
+func TestDiff(t *testing.T) {
+	oldCfg := config{
+		Server:   server{Host: "localhost", Port: 8080},
+		Tags:     []string{"a"},
+		Password: "old",
+		Ignored:  "old",
+		internal: "old",
+	}
+	newCfg := config{
+		Server:   server{Host: "localhost", Port: 9090},
+		Tags:     []string{"a", "b"},
+		Password: "new",
+		Ignored:  "new",
+		internal: "new",
+	}
+
+	expected := []Change{
+		{Path: "server.port", Old: 8080, New: 9090},
+		{Path: "tags", Old: []string{"a"}, New: []string{"a", "b"}},
+		{Path: "password", Old: secret.MaskedValue, New: secret.MaskedValue},
+	}
+	assert.Equal(t, expected, Diff(oldCfg, newCfg))
+	assert.Empty(t, Diff(oldCfg, oldCfg))
+	assert.Equal(t, expected, Diff(&oldCfg, &newCfg))
+}
@@ -0,0 +1,117 @@
+package dotenv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Parse parses a dotenv document into a map of variable names to values.
+//
+// Supported syntax:
+//   - KEY=VALUE lines (whitespace around the key and the value is trimmed)
+//   - an optional `export ` prefix before the key
+//   - blank lines and lines starting with `#`, and ` #` comments after unquoted values
+//   - values enclosed in single quotes, taken literally
+//   - values enclosed in double quotes, with \n, \r, \t, \", \\ and \$ escapes
+//   - quoted values spanning several lines
+func Parse(data []byte) (map[string]string, error) {
+	vars := make(map[string]string)
+
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNum := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			// The line is not quoted, since its value may be a secret.
+			if fields := strings.Fields(strings.TrimPrefix(line, "export ")); len(fields) > 1 {
+				return nil, fmt.Errorf("line %d: expected KEY=VALUE for %s", lineNum, fields[0])
+			}
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", lineNum)
+		}
+		key = strings.TrimSpace(strings.TrimPrefix(key, "export "))
+		if key == "" {
+			return nil, fmt.Errorf("line %d: empty variable name", lineNum)
+		}
+		value = strings.TrimSpace(value)
+
+		if value == "" || (value[0] != '"' && value[0] != '\'') {
+			vars[key] = stripComment(value)
+			continue
+		}
+
+		// Quoted value: read up to the closing quote, possibly on a later line.
+		quote := value[0]
+		rest := value[1:]
+		for {
+			if end := closingQuote(rest, quote); end >= 0 {
+				value = rest[:end]
+				break
+			}
+			i++
+			if i >= len(lines) {
+				return nil, fmt.Errorf("line %d: unterminated quoted value of %s", lineNum, key)
+			}
+			rest += "\n" + lines[i]
+		}
+
+		if quote == '"' {
+			value = unescape(value)
+		}
+		vars[key] = value
+	}
+
+	return vars, nil
+}
+
+// closingQuote returns the index of the quote closing a value in s,
+// or -1 if s does not contain it. Double quotes can be escaped with a backslash.
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quote == '"':
+			i++
+		case s[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// unescape resolves the escape sequences of a double-quoted value.
+func unescape(value string) string {
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i == len(value)-1 {
+			sb.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case '"', '\\', '$':
+			sb.WriteByte(value[i])
+		default:
+			sb.WriteByte('\\')
+			sb.WriteByte(value[i])
+		}
+	}
+	return sb.String()
+}
+
+// stripComment removes a ` #` comment from an unquoted value.
+func stripComment(value string) string {
+	if idx := strings.Index(value, " #"); idx >= 0 {
+		return strings.TrimSpace(value[:idx])
+	}
+	return value
+}
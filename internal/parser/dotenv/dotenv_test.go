@@ -0,0 +1,128 @@
+package dotenv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	data := []byte(`
+# comment
+HOST=localhost
+PORT = 8080
+NAME="quoted value"
+RAW='single quoted'
+EMPTY=
+`)
+
+	vars, err := Parse(data)
+	require.NoError(t, err)
+
+	expected := map[string]string{
+		"HOST":  "localhost",
+		"PORT":  "8080",
+		"NAME":  "quoted value",
+		"RAW":   "single quoted",
+		"EMPTY": "",
+	}
+	assert.Equal(t, expected, vars)
+}
+
+func TestParse_ExtendedSyntax(t *testing.T) {
+	data := []byte(`export HOST=localhost
+PORT=8080 # inline comment
+ESCAPED="line1\nline2\t\"quoted\""
+LITERAL='no \n escapes'
+MULTILINE="first
+second"
+KEY='-----BEGIN KEY-----
+abc
+-----END KEY-----'
+HASH="#not a comment"
+`)
+
+	vars, err := Parse(data)
+	require.NoError(t, err)
+
+	expected := map[string]string{
+		"HOST":      "localhost",
+		"PORT":      "8080",
+		"ESCAPED":   "line1\nline2\t\"quoted\"",
+		"LITERAL":   `no \n escapes`,
+		"MULTILINE": "first\nsecond",
+		"KEY":       "-----BEGIN KEY-----\nabc\n-----END KEY-----",
+		"HASH":      "#not a comment",
+	}
+	assert.Equal(t, expected, vars)
+}
+
+func TestParse_Unterminated(t *testing.T) {
+	_, err := Parse([]byte("KEY=\"value\nOTHER=1\n"))
+	assert.Error(t, err)
+}
+
+func TestParse_InvalidLine(t *testing.T) {
+	_, err := Parse([]byte("HOST=localhost\nINVALID\n"))
+	assert.Error(t, err)
+
+	// Значение строки не попадает в ошибку
+	_, err = Parse([]byte("DB_PASSWORD hunter2\n"))
+	assert.EqualError(t, err, "line 1: expected KEY=VALUE for DB_PASSWORD")
+	_, err = Parse([]byte("hunter2\n"))
+	assert.EqualError(t, err, "line 1: expected KEY=VALUE")
+}
+
+func TestGenerateDotenvTemplate(t *testing.T) {
+	type Server struct {
+		Host string `mapstructure:"host" default:"0.0.0.0" help:"Server host"`
+		Port int    `mapstructure:"port" default:"8080"`
+	}
+	type Config struct {
+		Server Server   `mapstructure:"server" env:"srv"`
+		Name   string   `mapstructure:"name" default:"my app" help:"App name"`
+		Tags   []string `mapstructure:"tags"`
+	}
+
+	expected := `# Server host
+SRV_HOST=0.0.0.0
+SRV_PORT=8080
+# App name
+NAME='my app'
+TAGS=
+`
+	assert.Equal(t, expected, GenerateDotenvTemplate(Config{}, true))
+}
+
+func TestGenerateDotenvTemplate_CompositeFields(t *testing.T) {
+	type Item struct {
+		Name string `mapstructure:"name"`
+	}
+	type Config struct {
+		Labels map[string]string `mapstructure:"labels" help:"Labels"`
+		Items  []Item            `mapstructure:"items"`
+		Tags   []string          `mapstructure:"tags" default:"a,b"`
+	}
+
+	expected := `# Labels
+# LABELS='{"key":"value"}'
+# ITEMS='[{"Name":""}]'
+TAGS=a,b
+`
+	assert.Equal(t, expected, GenerateDotenvTemplate(Config{}, true))
+}
+
+func TestGenerateDotenvTemplate_JSONArrayDefaults(t *testing.T) {
+	type Config struct {
+		Ports  []int    `mapstructure:"ports" default:"[1,2]"`
+		Hosts  []string `mapstructure:"hosts" default:"[\"a\", \"b\"]"`
+		Groups []string `mapstructure:"groups" default:"[\"a,b\"]"`
+	}
+
+	expected := `PORTS=1,2
+HOSTS=a,b
+GROUPS='["a,b"]'
+`
+	assert.Equal(t, expected, GenerateDotenvTemplate(Config{}, false))
+}
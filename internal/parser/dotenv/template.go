@@ -0,0 +1,76 @@
+package dotenv
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/vsysa/configo/internal/parser/env"
+	"github.com/vsysa/configo/internal/parser/secret"
+)
+
+// GenerateDotenvTemplate generates a dotenv template from a given configuration struct:
+// one VAR=default line per environment variable (see env.GetEnvs), optionally
+// preceded by the help text as a comment. Maps and slices of structs are
+// commented out, since they cannot be decoded from env vars.
+func GenerateDotenvTemplate(cfg interface{}, printDescription bool) string {
+	var sb strings.Builder
+	for _, info := range env.GetEnvs(cfg) {
+		if printDescription && info.HelpText != "" {
+			sb.WriteString("# " + info.HelpText + "\n")
+		}
+		value := formatValue(info.DefaultValue)
+		if info.Secret {
+			value = secret.Placeholder
+		}
+		if info.Composite {
+			// Maps and slices of structs cannot be set through env vars,
+			// so their lines are only shown as an example.
+			sb.WriteString("# ")
+		}
+		sb.WriteString(info.EnvVar + "=" + value + "\n")
+	}
+	return sb.String()
+}
+
+// formatValue renders a default value for a dotenv file. Slices written as
+// JSON arrays are turned into the comma-separated form env vars are decoded
+// from, empty slices are left empty, and values with special characters are
+// enclosed in single quotes.
+func formatValue(value string) string {
+	if value == "[]" {
+		return ""
+	}
+	if items, ok := commaSeparated(value); ok {
+		value = items
+	}
+	if strings.ContainsAny(value, " \t#\"'{}$\\") && !strings.Contains(value, "'") {
+		return "'" + value + "'"
+	}
+	return value
+}
+
+// commaSeparated converts a JSON array of scalars, e.g. `[1,2]`, to `1,2`.
+// It reports false for other values and for items containing a comma.
+func commaSeparated(value string) (string, bool) {
+	if !strings.HasPrefix(value, "[") {
+		return "", false
+	}
+	var items []interface{}
+	if err := json.Unmarshal([]byte(value), &items); err != nil {
+		return "", false
+	}
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		switch item.(type) {
+		case map[string]interface{}, []interface{}, nil:
+			return "", false
+		}
+		s := fmt.Sprint(item)
+		if strings.Contains(s, ",") {
+			return "", false
+		}
+		out = append(out, s)
+	}
+	return strings.Join(out, ","), true
+}
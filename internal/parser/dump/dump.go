@@ -0,0 +1,180 @@
+package dump
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/vsysa/configo/internal/parser/env"
+	"github.com/vsysa/configo/internal/parser/secret"
+)
+
+// MaskedValue replaces masked values in the dump.
+const MaskedValue = secret.MaskedValue
+
+// ToMap converts a config struct into a nested map keyed the way the config
+// is read, i.e. by the mapstructure keys (bind keys). Values for which mask
+// reports true, as well as secret fields, are replaced with MaskedValue.
+//
+// Durations and other values implementing encoding.TextMarshaler are rendered
+// as text, so that the dump can be read back as a config file.
+func ToMap(cfg interface{}, mask func(bindKey string) bool) map[string]interface{} {
+	v := reflect.ValueOf(cfg)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return map[string]interface{}{}
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return map[string]interface{}{}
+	}
+	return structToMap(v, "", mask)
+}
+
+func structToMap(v reflect.Value, parentBindKey string, mask func(bindKey string) bool) map[string]interface{} {
+	t := v.Type()
+	out := make(map[string]interface{}, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		// Skip unexported and ignored fields.
+		if field.PkgPath != "" || field.Tag.Get("mapstructure") == "-" {
+			continue
+		}
+
+		key := getMapstructureKey(field)
+		bindKey := key
+		if parentBindKey != "" {
+			bindKey = parentBindKey + "." + key
+		}
+
+		if secret.IsSecretField(field) || (mask != nil && mask(bindKey)) {
+			out[key] = MaskedValue
+			continue
+		}
+		out[key] = toValue(v.Field(i), bindKey, mask)
+	}
+	return out
+}
+
+// toValue converts a field value into plain maps, slices and scalars.
+func toValue(v reflect.Value, bindKey string, mask func(bindKey string) bool) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		return time.Duration(v.Int()).String()
+	}
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok && v.Kind() != reflect.Ptr {
+		if text, err := m.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return toValue(v.Elem(), bindKey, mask)
+	case reflect.Struct:
+		return structToMap(v, bindKey, mask)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return []interface{}{}
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = toValue(v.Index(i), bindKey, mask)
+		}
+		return items
+	case reflect.Map:
+		out := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			if mask != nil && mask(bindKey+"."+key) {
+				out[key] = MaskedValue
+				continue
+			}
+			out[key] = toValue(iter.Value(), bindKey+"."+key, mask)
+		}
+		return out
+	default:
+		return v.Interface()
+	}
+}
+
+// EnvLines renders the values as KEY=value lines, one per environment
+// variable of cfg (see env.GetEnvs). Slices are joined with commas,
+// maps are written as JSON.
+func EnvLines(cfg interface{}, values map[string]interface{}) string {
+	var sb strings.Builder
+	for _, info := range env.GetEnvs(cfg) {
+		value, ok := lookupValue(values, info.BindKey)
+		if !ok {
+			continue
+		}
+		sb.WriteString(info.EnvVar + "=" + quote(formatEnvValue(value)) + "\n")
+	}
+	return sb.String()
+}
+
+func formatEnvValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = formatEnvValue(item)
+		}
+		return strings.Join(items, ",")
+	case map[string]interface{}:
+		// Maps are written as JSON, the format of map `default` tags.
+		out, _ := json.Marshal(v)
+		return string(out)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// quote encloses values with special characters in single quotes
+// or, if the value contains single quotes itself, in escaped double quotes.
+func quote(value string) string {
+	if !strings.ContainsAny(value, " \t\n#\"'{}$\\") {
+		return value
+	}
+	if !strings.ContainsAny(value, "'\n") {
+		return "'" + value + "'"
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`)
+	return `"` + replacer.Replace(value) + `"`
+}
+
+func lookupValue(values map[string]interface{}, bindKey string) (interface{}, bool) {
+	var current interface{} = values
+	for _, key := range strings.Split(bindKey, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// getMapstructureKey returns the key Viper reads the field from:
+// the mapstructure tag or the lowercase field name.
+func getMapstructureKey(field reflect.StructField) string {
+	if key := field.Tag.Get("mapstructure"); key != "" {
+		return key
+	}
+	return strings.ToLower(field.Name)
+}
@@ -0,0 +1,63 @@
+package dump
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type server struct {
+	Host string `mapstructure:"host" env:"srv_host"`
+	Port int
+}
+
+type config struct {
+	Server   server            `mapstructure:"server"`
+	Password string            `mapstructure:"password"`
+	Timeout  time.Duration     `mapstructure:"timeout"`
+	Tags     []string          `mapstructure:"tags"`
+	Labels   map[string]string `mapstructure:"labels"`
+	Ignored  string            `mapstructure:"-"`
+	internal string
+}
+
+func TestToMap(t *testing.T) {
+	cfg := config{
+		Server:   server{Host: "localhost", Port: 8080},
+		Password: "s3cret",
+		Timeout:  time.Minute,
+		Tags:     []string{"a", "b"},
+		Labels:   map[string]string{"team": "core", "token": "abc"},
+		Ignored:  "ignored",
+		internal: "internal",
+	}
+
+	mask := func(bindKey string) bool { return bindKey == "password" || bindKey == "labels.token" }
+	expected := map[string]interface{}{
+		"server":   map[string]interface{}{"host": "localhost", "port": 8080},
+		"password": MaskedValue,
+		"timeout":  "1m0s",
+		"tags":     []interface{}{"a", "b"},
+		"labels":   map[string]interface{}{"team": "core", "token": MaskedValue},
+	}
+	assert.Equal(t, expected, ToMap(cfg, mask))
+}
+
+func TestEnvLines(t *testing.T) {
+	cfg := config{
+		Server:   server{Host: "my host", Port: 8080},
+		Password: "it's",
+		Tags:     []string{"a", "b"},
+		Labels:   map[string]string{"team": "core"},
+	}
+
+	expected := `SERVER_SRV_HOST='my host'
+SERVER_PORT=8080
+PASSWORD="it's"
+TIMEOUT=0s
+TAGS=a,b
+LABELS='{"team":"core"}'
+`
+	assert.Equal(t, expected, EnvLines(cfg, ToMap(cfg, nil)))
+}
@@ -4,18 +4,25 @@ import (
 	"encoding/json"
 	"reflect"
 	"strings"
+
+	"github.com/vsysa/configo/internal/parser/secret"
 )
 
 // EnvInfo holds information needed to document an environment variable:
 //   - EnvVar:       the name of the environment variable.
 //   - DefaultValue: the default value (if any).
 //   - HelpText:     description/help for the variable.
+//   - Secret:       whether the value is a secret that must not be printed.
+//   - Composite:    whether the field is a map or a slice of structs, which
+//     cannot be decoded from the string value of an environment variable.
 type EnvInfo struct {
 	EnvVar       string
 	DefaultValue string
 	HelpText     string
 	BindKey      string
 	ValueType    string
+	Secret       bool
+	Composite    bool
 }
 
 func GetEnvs(cfg interface{}) []EnvInfo {
@@ -89,6 +96,7 @@ func parseEnvStructure(t reflect.Type, parentEnvPrefix, parentBindKey string, li
 			BindKey:   childBindKey,
 			HelpText:  getHelpText(field.Tag),
 			ValueType: field.Type.String(), // e.g. "int", "[]string", "map[string]int"
+			Secret:    secret.IsSecretField(field),
 		}
 
 		// Figure out the default value. If none is provided, handle special cases for map/slice.
@@ -98,6 +106,7 @@ func parseEnvStructure(t reflect.Type, parentEnvPrefix, parentBindKey string, li
 
 		// ======================= SLICE CASE =======================
 		case reflect.Slice:
+			info.Composite = field.Type.Elem().Kind() == reflect.Struct
 			if defaultValStr == "" {
 				// If no default, produce a "zero" JSON.
 				elemKind := field.Type.Elem().Kind()
@@ -119,6 +128,7 @@ func parseEnvStructure(t reflect.Type, parentEnvPrefix, parentBindKey string, li
 
 		// ======================= MAP CASE ==========================
 		case reflect.Map:
+			info.Composite = true
 			if defaultValStr == "" {
 				// No default => produce `{"key":"value"}`
 				defaultValStr = `{"key":"value"}`
@@ -0,0 +1,139 @@
+package flags
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+
+	"github.com/vsysa/configo/internal/parser/secret"
+)
+
+// FlagInfo holds information needed to register and document a command-line flag:
+//   - Name:         the name of the flag, e.g. "server.port".
+//   - DefaultValue: the default value (if any), shown in the usage.
+//   - HelpText:     description/help for the flag.
+//   - BindKey:      the Viper bind key the flag sets.
+//   - IsBool:       whether the flag is a boolean switch (`--enable`).
+//   - Secret:       whether the value is a secret that must not be printed.
+type FlagInfo struct {
+	Name         string
+	DefaultValue string
+	HelpText     string
+	BindKey      string
+	ValueType    string
+	IsBool       bool
+	Secret       bool
+}
+
+func GetFlags(cfg interface{}) []FlagInfo {
+	var lines []FlagInfo
+	parseFlagStructure(reflect.TypeOf(cfg), "", "", &lines)
+	return lines
+}
+
+// NewFlagSet creates a flag.FlagSet with one flag per config field. Boolean fields
+// become boolean flags, all other fields string flags whose values are decoded
+// the same way as environment variables. Defaults of secret fields are masked
+// in the usage; they are only shown there, since unset flags are not read.
+func NewFlagSet(cfg interface{}, name string, errorHandling flag.ErrorHandling) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, errorHandling)
+	for _, info := range GetFlags(cfg) {
+		if info.IsBool {
+			fs.Bool(info.Name, info.DefaultValue == "true", info.HelpText)
+			continue
+		}
+		defaultValue := info.DefaultValue
+		if info.Secret && defaultValue != "" {
+			defaultValue = secret.MaskedValue
+		}
+		fs.String(info.Name, defaultValue, info.HelpText)
+	}
+	return fs
+}
+
+// parseFlagStructure recursively scans the given type (and nested structs, if any),
+// collecting flag information. Flag names are the bind keys by default, e.g.
+// "server.port"; a `flag:"..."` tag on a field renames it, on a struct it
+// replaces the prefix of the nested flags, and `flag:"-"` skips the field
+// (or the whole struct).
+func parseFlagStructure(t reflect.Type, parentFlagPrefix, parentBindKey string, lines *[]FlagInfo) {
+	// If the type is a pointer, unwrap it to its element type.
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		// Skip unexported fields
+		if field.PkgPath != "" {
+			continue
+		}
+
+		flagName, flagAllowed := getFlagName(field)
+		if !flagAllowed {
+			continue
+		}
+
+		msKey := getMapstructureKey(field)
+
+		childFlagName := flagName
+		if parentFlagPrefix != "" {
+			childFlagName = parentFlagPrefix + "." + flagName
+		}
+
+		childBindKey := msKey
+		if parentBindKey != "" {
+			childBindKey = parentBindKey + "." + msKey
+		}
+
+		if field.Type.Kind() == reflect.Struct {
+			// Recurse into nested struct.
+			parseFlagStructure(field.Type, childFlagName, childBindKey, lines)
+			continue
+		}
+
+		*lines = append(*lines, FlagInfo{
+			Name:         childFlagName,
+			DefaultValue: field.Tag.Get("default"),
+			HelpText:     field.Tag.Get("help"),
+			BindKey:      childBindKey,
+			ValueType:    field.Type.String(),
+			IsBool:       field.Type.Kind() == reflect.Bool,
+			Secret:       secret.IsSecretField(field),
+		})
+	}
+}
+
+// getFlagName determines the name of the flag.
+// Priority:
+// 1. flag:"..." tag (excluding "-")
+// 2. mapstructure:"..." tag
+// 3. field name => lowercase
+func getFlagName(field reflect.StructField) (flagName string, isAllowFlag bool) {
+	flagName = field.Tag.Get("flag")
+	if flagName == "-" {
+		return "", false
+	}
+	if flagName != "" {
+		return flagName, true
+	}
+	if field.Tag.Get("mapstructure") == "-" {
+		return "", false
+	}
+	return getMapstructureKey(field), true
+}
+
+// getMapstructureKey returns the part of the key used for Viper bind keys
+// based on mapstructure or the field name.
+func getMapstructureKey(field reflect.StructField) string {
+	msVal := field.Tag.Get("mapstructure")
+	if msVal == "" {
+		// fallback to the lowercase field name
+		return strings.ToLower(field.Name)
+	}
+	return msVal
+}
@@ -0,0 +1,48 @@
+package flags
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFlags(t *testing.T) {
+	type Server struct {
+		Host string `mapstructure:"host" default:"0.0.0.0" help:"Server host"`
+		Port int    `mapstructure:"port" flag:"listen-port" default:"8080"`
+	}
+	type Config struct {
+		Server  Server `mapstructure:"server"`
+		Admin   Server `mapstructure:"admin" flag:"adm"`
+		Hidden  Server `mapstructure:"hidden" flag:"-"`
+		Debug   bool   `mapstructure:"debug" help:"Debug mode"`
+		Secret  string `mapstructure:"secret" flag:"-"`
+		Ignored string `mapstructure:"-"`
+	}
+
+	expected := []FlagInfo{
+		{Name: "server.host", DefaultValue: "0.0.0.0", HelpText: "Server host", BindKey: "server.host", ValueType: "string"},
+		{Name: "server.listen-port", DefaultValue: "8080", BindKey: "server.port", ValueType: "int"},
+		{Name: "adm.host", DefaultValue: "0.0.0.0", HelpText: "Server host", BindKey: "admin.host", ValueType: "string"},
+		{Name: "adm.listen-port", DefaultValue: "8080", BindKey: "admin.port", ValueType: "int"},
+		{Name: "debug", HelpText: "Debug mode", BindKey: "debug", ValueType: "bool", IsBool: true},
+	}
+
+	assert.Equal(t, expected, GetFlags(Config{}))
+}
+
+func TestNewFlagSet(t *testing.T) {
+	type Config struct {
+		Port  int  `mapstructure:"port" default:"8080"`
+		Debug bool `mapstructure:"debug"`
+	}
+
+	fs := NewFlagSet(Config{}, "test", flag.ContinueOnError)
+	require.NoError(t, fs.Parse([]string{"--port=9090", "--debug"}))
+
+	assert.Equal(t, "9090", fs.Lookup("port").Value.String())
+	assert.Equal(t, "true", fs.Lookup("debug").Value.String())
+	assert.Equal(t, "8080", fs.Lookup("port").DefValue)
+}
@@ -0,0 +1,135 @@
+package json
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/vsysa/configo/internal/parser/secret"
+)
+
+// GenerateJSONTemplate generates an indented JSON template from a given configuration struct.
+// Fields keep their declaration order and get the values of their `default` tags,
+// or null (an empty list / a sample entry for slices and maps) when there is none.
+// JSON has no comments, so help texts are not rendered.
+func GenerateJSONTemplate(cfg interface{}) string {
+	var sb strings.Builder
+	writeStruct(&sb, reflect.TypeOf(cfg), 0)
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// writeStruct writes a struct type as a JSON object.
+func writeStruct(sb *strings.Builder, t reflect.Type, indent int) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		sb.WriteString("{}")
+		return
+	}
+
+	var fields []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		// Skip unexported and ignored fields.
+		if field.PkgPath != "" || field.Tag.Get("mapstructure") == "-" {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		sb.WriteString("{}")
+		return
+	}
+
+	sb.WriteString("{\n")
+	for i, field := range fields {
+		sb.WriteString(strings.Repeat("  ", indent+1))
+		sb.WriteString(strconv.Quote(getFieldName(field)) + ": ")
+		writeField(sb, field, indent+1)
+		if i < len(fields)-1 {
+			sb.WriteString(",")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(strings.Repeat("  ", indent) + "}")
+}
+
+// writeField writes the template value of a single field.
+func writeField(sb *strings.Builder, field reflect.StructField, indent int) {
+	if secret.IsSecretField(field) {
+		sb.WriteString(strconv.Quote(secret.Placeholder))
+		return
+	}
+	defaultValue := field.Tag.Get("default")
+
+	switch field.Type.Kind() {
+	case reflect.Struct:
+		writeStruct(sb, field.Type, indent)
+
+	case reflect.Slice:
+		elem := field.Type.Elem()
+		if elem.Kind() == reflect.Struct {
+			// A single zero-value element as a placeholder.
+			sb.WriteString("[\n" + strings.Repeat("  ", indent+1))
+			writeStruct(sb, elem, indent+1)
+			sb.WriteString("\n" + strings.Repeat("  ", indent) + "]")
+			return
+		}
+		if defaultValue == "" {
+			sb.WriteString("[]")
+			return
+		}
+		if json.Valid([]byte(defaultValue)) && strings.HasPrefix(defaultValue, "[") {
+			sb.WriteString(defaultValue)
+			return
+		}
+		items := strings.Split(defaultValue, ",")
+		for i, item := range items {
+			items[i] = formatPrimitive(elem.Kind(), strings.TrimSpace(item))
+		}
+		sb.WriteString("[" + strings.Join(items, ", ") + "]")
+
+	case reflect.Map:
+		if defaultValue != "" && json.Valid([]byte(defaultValue)) {
+			sb.WriteString(defaultValue)
+			return
+		}
+		sb.WriteString(`{"key": "value"}`)
+
+	default:
+		sb.WriteString(formatPrimitive(field.Type.Kind(), defaultValue))
+	}
+}
+
+// formatPrimitive renders a default value as a JSON literal of the given kind.
+// Values that do not parse as their kind (e.g. durations like "1m5s") are quoted.
+func formatPrimitive(kind reflect.Kind, value string) string {
+	if value == "" {
+		return "null"
+	}
+	switch kind {
+	case reflect.Bool:
+		if _, err := strconv.ParseBool(value); err == nil {
+			return value
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return value
+		}
+	}
+	return strconv.Quote(value)
+}
+
+// getFieldName returns the key of the field as Viper decodes it:
+// the mapstructure tag or the lowercase field name.
+func getFieldName(field reflect.StructField) string {
+	if name := field.Tag.Get("mapstructure"); name != "" {
+		return name
+	}
+	return strings.ToLower(field.Name)
+}
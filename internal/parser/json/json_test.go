@@ -0,0 +1,49 @@
+package json
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateJSONTemplate(t *testing.T) {
+	type Item struct {
+		Name string `mapstructure:"name" default:"item"`
+	}
+	type Config struct {
+		Host     string            `mapstructure:"host" default:"localhost" help:"The hostname"`
+		Port     int               `mapstructure:"port" default:"8080"`
+		Enabled  bool              `mapstructure:"enabled" default:"true"`
+		Timeout  string            `mapstructure:"timeout"`
+		Options  []int             `mapstructure:"options" default:"1,2,3"`
+		Settings map[string]string `mapstructure:"settings"`
+		Items    []Item            `mapstructure:"items"`
+		Meta     struct {
+			Version string `mapstructure:"version" default:"1.0"`
+		} `mapstructure:"meta"`
+		Ignored string `mapstructure:"-" default:"ignored"`
+	}
+
+	jsonTemplate := GenerateJSONTemplate(Config{})
+
+	expected := `{
+  "host": "localhost",
+  "port": 8080,
+  "enabled": true,
+  "timeout": null,
+  "options": [1, 2, 3],
+  "settings": {"key": "value"},
+  "items": [
+    {
+      "name": "item"
+    }
+  ],
+  "meta": {
+    "version": "1.0"
+  }
+}
+`
+	assert.Equal(t, expected, jsonTemplate)
+	assert.True(t, json.Valid([]byte(jsonTemplate)))
+}
@@ -0,0 +1,140 @@
+package secret
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	// MaskedValue replaces secret values in printed configs, dumps and help output.
+	MaskedValue = "******"
+	// Placeholder stands for secret values in generated templates.
+	Placeholder = "<secret>"
+)
+
+// Secret is a string that is masked whenever it is printed or marshaled.
+// Use Value to get the actual value.
+type Secret string
+
+// Value returns the actual, unmasked value.
+func (s Secret) Value() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return MaskedValue
+}
+
+func (s Secret) GoString() string {
+	return fmt.Sprintf("%q", s.String())
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+var secretType = reflect.TypeOf(Secret(""))
+
+// IsSecretField reports whether the field holds a secret: it is tagged with
+// `secret:"true"` or is of type Secret.
+func IsSecretField(field reflect.StructField) bool {
+	return field.Tag.Get("secret") == "true" || field.Type == secretType
+}
+
+// BindKeys returns the bind keys of the secret fields of cfg, e.g. "database.password".
+func BindKeys(cfg interface{}) []string {
+	var keys []string
+	collectBindKeys(reflect.TypeOf(cfg), "", &keys)
+	return keys
+}
+
+func collectBindKeys(t reflect.Type, parentBindKey string, keys *[]string) {
+	if t == nil {
+		return
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || field.Tag.Get("mapstructure") == "-" {
+			continue
+		}
+
+		bindKey := getMapstructureKey(field)
+		if parentBindKey != "" {
+			bindKey = parentBindKey + "." + bindKey
+		}
+
+		if IsSecretField(field) {
+			*keys = append(*keys, bindKey)
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct {
+			collectBindKeys(field.Type, bindKey, keys)
+		}
+	}
+}
+
+// Redact returns a copy of cfg with the secret string fields set to MaskedValue
+// and other secret fields set to their zero value. Non-struct values are
+// returned as is.
+func Redact[T any](cfg T) T {
+	v := reflect.ValueOf(&cfg).Elem()
+	redactValue(v)
+	return cfg
+}
+
+func redactValue(v reflect.Value) {
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		fv := v.Field(i)
+		if IsSecretField(field) {
+			if fv.Kind() == reflect.String {
+				if fv.Len() > 0 {
+					fv.SetString(MaskedValue)
+				}
+			} else {
+				fv.Set(reflect.Zero(field.Type))
+			}
+			continue
+		}
+		if fv.Kind() == reflect.Struct {
+			redactValue(fv)
+		}
+	}
+}
+
+// Mask replaces every occurrence of the given secret values in s with MaskedValue.
+func Mask(s string, values []string) string {
+	for _, value := range values {
+		if value != "" {
+			s = strings.ReplaceAll(s, value, MaskedValue)
+		}
+	}
+	return s
+}
+
+// getMapstructureKey returns the part of the bind key of the field:
+// the mapstructure tag or the lowercase field name.
+func getMapstructureKey(field reflect.StructField) string {
+	if key := field.Tag.Get("mapstructure"); key != "" {
+		return key
+	}
+	return strings.ToLower(field.Name)
+}
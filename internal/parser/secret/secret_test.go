@@ -0,0 +1,56 @@
+package secret
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type database struct {
+	User     string `mapstructure:"user"`
+	Password string `mapstructure:"password" secret:"true"`
+	PIN      int    `secret:"true"`
+}
+
+type config struct {
+	Database database `mapstructure:"database"`
+	Token    Secret   `mapstructure:"token"`
+	Ignored  Secret   `mapstructure:"-"`
+}
+
+func TestSecret(t *testing.T) {
+	s := Secret("s3cret")
+
+	assert.Equal(t, "s3cret", s.Value())
+	assert.Equal(t, MaskedValue, fmt.Sprint(s))
+	assert.Equal(t, `"******"`, fmt.Sprintf("%#v", s))
+
+	out, err := json.Marshal(struct{ Token Secret }{s})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"Token":"******"}`, string(out))
+
+	assert.Equal(t, "", Secret("").String())
+}
+
+func TestBindKeys(t *testing.T) {
+	assert.Equal(t, []string{"database.password", "database.pin", "token"}, BindKeys(config{}))
+}
+
+func TestRedact(t *testing.T) {
+	cfg := config{
+		Database: database{User: "user", Password: "s3cret", PIN: 1234},
+		Token:    "token",
+	}
+
+	redacted := Redact(cfg)
+	assert.Equal(t, database{User: "user", Password: MaskedValue}, redacted.Database)
+	assert.Equal(t, "s3cret", cfg.Database.Password, "the original must not be modified")
+	assert.NotContains(t, fmt.Sprintf("%+v", redacted), "s3cret")
+	assert.NotContains(t, fmt.Sprintf("%+v", redacted), "token}")
+}
+
+func TestMask(t *testing.T) {
+	assert.Equal(t, "invalid password ******", Mask("invalid password s3cret", []string{"s3cret", ""}))
+}
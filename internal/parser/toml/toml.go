@@ -0,0 +1,185 @@
+package toml
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vsysa/configo/internal/parser/secret"
+)
+
+// bareKey matches keys that do not need quoting in TOML.
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// GenerateTOMLTemplate generates a TOML template from a given configuration struct.
+// Plain fields become `key = value` lines with the values of their `default` tags
+// (or the zero value, since TOML has no null), nested structs and maps become
+// tables and slices of structs become arrays of tables. Help texts are written
+// as comments above the keys.
+func GenerateTOMLTemplate(cfg interface{}, printDescription bool) string {
+	var sb strings.Builder
+	writeTable(&sb, reflect.TypeOf(cfg), "", printDescription)
+	return strings.TrimLeft(sb.String(), "\n")
+}
+
+// writeTable writes the fields of a struct type. TOML requires the plain keys of
+// a table to precede its sub-tables, so the sub-tables are written afterwards.
+func writeTable(sb *strings.Builder, t reflect.Type, tableKey string, printDescription bool) {
+	t = indirect(t)
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	var tables []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		// Skip unexported and ignored fields.
+		if field.PkgPath != "" || field.Tag.Get("mapstructure") == "-" {
+			continue
+		}
+
+		kind := indirect(field.Type).Kind()
+		if kind == reflect.Struct || kind == reflect.Map ||
+			(kind == reflect.Slice && indirect(field.Type.Elem()).Kind() == reflect.Struct) {
+			tables = append(tables, field)
+			continue
+		}
+
+		writeComment(sb, field, printDescription)
+		sb.WriteString(formatKey(getFieldName(field)) + " = " + formatField(field) + "\n")
+	}
+
+	for _, field := range tables {
+		fullKey := formatKey(getFieldName(field))
+		if tableKey != "" {
+			fullKey = tableKey + "." + fullKey
+		}
+
+		sb.WriteString("\n")
+		writeComment(sb, field, printDescription)
+
+		switch indirect(field.Type).Kind() {
+		case reflect.Struct:
+			sb.WriteString("[" + fullKey + "]\n")
+			writeTable(sb, field.Type, fullKey, printDescription)
+		case reflect.Slice:
+			sb.WriteString("[[" + fullKey + "]]\n")
+			writeTable(sb, indirect(field.Type).Elem(), fullKey, printDescription)
+		case reflect.Map:
+			sb.WriteString("[" + fullKey + "]\n")
+			writeMapEntries(sb, field)
+		}
+	}
+}
+
+// indirect returns the element type of a pointer type, so that pointers to
+// structs are written as tables like the structs themselves.
+func indirect(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
+
+// writeMapEntries writes the entries of a JSON map default, or a sample entry.
+func writeMapEntries(sb *strings.Builder, field reflect.StructField) {
+	var entries map[string]interface{}
+	if err := json.Unmarshal([]byte(field.Tag.Get("default")), &entries); err != nil || len(entries) == 0 {
+		sb.WriteString(`key = "value"` + "\n")
+		return
+	}
+
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := entries[key]
+		if str, ok := value.(string); ok {
+			sb.WriteString(formatKey(key) + " = " + strconv.Quote(str) + "\n")
+		} else {
+			sb.WriteString(formatKey(key) + " = " + fmt.Sprint(value) + "\n")
+		}
+	}
+}
+
+func writeComment(sb *strings.Builder, field reflect.StructField, printDescription bool) {
+	if help := field.Tag.Get("help"); printDescription && help != "" {
+		sb.WriteString("# " + help + "\n")
+	}
+}
+
+// formatField renders the default value of a plain or slice field as a TOML value.
+func formatField(field reflect.StructField) string {
+	if secret.IsSecretField(field) {
+		return strconv.Quote(secret.Placeholder)
+	}
+	defaultValue := field.Tag.Get("default")
+
+	if field.Type.Kind() == reflect.Slice {
+		if defaultValue == "" {
+			return "[]"
+		}
+		var items []string
+		var jsonItems []interface{}
+		if strings.HasPrefix(defaultValue, "[") && json.Unmarshal([]byte(defaultValue), &jsonItems) == nil {
+			for _, item := range jsonItems {
+				items = append(items, fmt.Sprint(item))
+			}
+		} else {
+			items = strings.Split(defaultValue, ",")
+		}
+		for i, item := range items {
+			items[i] = formatPrimitive(field.Type.Elem().Kind(), strings.TrimSpace(item))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+
+	return formatPrimitive(field.Type.Kind(), defaultValue)
+}
+
+// formatPrimitive renders a default value as a TOML literal of the given kind.
+// Empty values become the zero value of the kind, values that do not parse
+// as their kind (e.g. durations like "1m5s") are quoted.
+func formatPrimitive(kind reflect.Kind, value string) string {
+	switch kind {
+	case reflect.Bool:
+		if value == "" {
+			return "false"
+		}
+		if _, err := strconv.ParseBool(value); err == nil {
+			return value
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if value == "" {
+			return "0"
+		}
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return value
+		}
+	}
+	return strconv.Quote(value)
+}
+
+func formatKey(key string) string {
+	if bareKey.MatchString(key) {
+		return key
+	}
+	return strconv.Quote(key)
+}
+
+// getFieldName returns the key of the field as Viper decodes it:
+// the mapstructure tag or the lowercase field name.
+func getFieldName(field reflect.StructField) string {
+	if name := field.Tag.Get("mapstructure"); name != "" {
+		return name
+	}
+	return strings.ToLower(field.Name)
+}
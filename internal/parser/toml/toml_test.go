@@ -0,0 +1,64 @@
+package toml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateTOMLTemplate(t *testing.T) {
+	type Item struct {
+		Name string `mapstructure:"name" default:"item"`
+	}
+	type Config struct {
+		Meta struct {
+			Version string `mapstructure:"version" default:"1.0" help:"App version"`
+		} `mapstructure:"meta" help:"Metadata"`
+		Host     string            `mapstructure:"host" default:"localhost" help:"The hostname"`
+		Port     int               `mapstructure:"port"`
+		Options  []string          `mapstructure:"options" default:"[\"a\",\"b\"]"`
+		Settings map[string]string `mapstructure:"settings" default:"{\"env\":\"prod\"}"`
+		Items    []Item            `mapstructure:"items"`
+	}
+
+	tomlTemplate := GenerateTOMLTemplate(Config{}, true)
+
+	expected := `# The hostname
+host = "localhost"
+port = 0
+options = ["a", "b"]
+
+# Metadata
+[meta]
+# App version
+version = "1.0"
+
+[settings]
+env = "prod"
+
+[[items]]
+name = "item"
+`
+	assert.Equal(t, expected, tomlTemplate)
+}
+
+func TestGenerateTOMLTemplate_PointerStructs(t *testing.T) {
+	type Item struct {
+		Name string `mapstructure:"name" default:"item"`
+	}
+	type Config struct {
+		Name  string  `mapstructure:"name"`
+		P     *Item   `mapstructure:"p"`
+		Items []*Item `mapstructure:"items"`
+	}
+
+	expected := `name = ""
+
+[p]
+name = "item"
+
+[[items]]
+name = "item"
+`
+	assert.Equal(t, expected, GenerateTOMLTemplate(Config{}, false))
+}
@@ -3,7 +3,10 @@ package yaml
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
+
+	"github.com/vsysa/configo/internal/parser/secret"
 )
 
 // fieldInfo represents a single line in the generated YAML template
@@ -17,10 +20,41 @@ type fieldInfo struct {
 // It scans the struct using reflection, collects information about each field,
 // and then produces YAML lines aligned with optional help text (comments).
 func GenerateYAMLTemplate(cfg interface{}, printDescription bool) string {
+	return GenerateProfileYAMLTemplate(cfg, printDescription, "")
+}
+
+// GenerateProfileYAMLTemplate generates a YAML template for the given profile.
+// Profile-specific `default_<profile>` tags take precedence over `default` tags,
+// and the template starts with a comment naming the profile.
+func GenerateProfileYAMLTemplate(cfg interface{}, printDescription bool, profile string) string {
+	return generateTemplate(cfg, printDescription, templateOptions{profile: profile})
+}
+
+// GenerateYAMLTemplateWithValues generates a YAML template whose default values
+// are taken from values, a nested map keyed by bind keys (e.g. a decoded
+// defaults.yml), falling back to the `default` tags for keys values does not set.
+func GenerateYAMLTemplateWithValues(cfg interface{}, printDescription bool, values map[string]interface{}) string {
+	return generateTemplate(cfg, printDescription, templateOptions{values: values})
+}
+
+// templateOptions controls where the template takes its default values from.
+type templateOptions struct {
+	// profile selects `default_<profile>` tags over `default` tags.
+	profile string
+	// values holds default values keyed by nested bind keys,
+	// which take precedence over the tags.
+	values map[string]interface{}
+}
+
+func generateTemplate(cfg interface{}, printDescription bool, opts templateOptions) string {
 	var lines []fieldInfo
 
+	if opts.profile != "" {
+		lines = append(lines, fieldInfo{Line: fmt.Sprintf("# Profile: %s", opts.profile)})
+	}
+
 	// First pass: Parse the struct and collect the lines
-	parseStructure(reflect.TypeOf(cfg), reflect.ValueOf(cfg), 0, &lines)
+	parseStructure(reflect.TypeOf(cfg), reflect.ValueOf(cfg), 0, "", opts, &lines)
 
 	// Second pass: Align the resulting YAML lines with help comments
 	return generateYAMLWithAlignment(lines, printDescription)
@@ -28,7 +62,7 @@ func GenerateYAMLTemplate(cfg interface{}, printDescription bool) string {
 
 // parseStructure recursively traverses a struct (and nested structs)
 // to build a list of fieldInfo lines that represent the YAML structure.
-func parseStructure(t reflect.Type, v reflect.Value, indent int, lines *[]fieldInfo) {
+func parseStructure(t reflect.Type, v reflect.Value, indent int, parentBindKey string, opts templateOptions, lines *[]fieldInfo) {
 	indentation := strings.Repeat("  ", indent)
 
 	for i := 0; i < t.NumField(); i++ {
@@ -48,9 +82,11 @@ func parseStructure(t reflect.Type, v reflect.Value, indent int, lines *[]fieldI
 
 		// Determine the YAML (and Viper) key name.
 		fieldName := getFieldName(field)
+		bindKey := getBindKey(parentBindKey, field)
 
 		// Retrieve default value (if any).
-		defaultValue := getDefaultValue(tag)
+		defaultValue := getDefaultValue(tag, opts.profile)
+		value, hasValue := lookupValue(opts.values, bindKey)
 
 		// Retrieve help text (if any).
 		helpText := getHelpText(tag)
@@ -62,7 +98,7 @@ func parseStructure(t reflect.Type, v reflect.Value, indent int, lines *[]fieldI
 				Line: fmt.Sprintf("%s%s:", indentation, fieldName),
 				Help: helpText,
 			})
-			parseStructure(field.Type, v.Field(i), indent+1, lines)
+			parseStructure(field.Type, v.Field(i), indent+1, bindKey, opts, lines)
 
 		case reflect.Slice:
 			// For slices, we append the slice name and then handle struct slices vs. primitive slices.
@@ -77,7 +113,14 @@ func parseStructure(t reflect.Type, v reflect.Value, indent int, lines *[]fieldI
 					Line: fmt.Sprintf("%s  -", indentation),
 					Help: "",
 				})
-				parseStructure(field.Type.Elem(), reflect.Zero(field.Type.Elem()), indent+2, lines)
+				parseStructure(field.Type.Elem(), reflect.Zero(field.Type.Elem()), indent+2, "", templateOptions{profile: opts.profile}, lines)
+			} else if items, ok := value.([]interface{}); hasValue && ok {
+				for _, item := range items {
+					*lines = append(*lines, fieldInfo{
+						Line: fmt.Sprintf("%s  - %v", indentation, item),
+						Help: "",
+					})
+				}
 			} else {
 				// For slices of primitives, we try to split the default value by commas.
 				if defaultValue != "" {
@@ -104,6 +147,20 @@ func parseStructure(t reflect.Type, v reflect.Value, indent int, lines *[]fieldI
 				Line: fmt.Sprintf("%s%s:", indentation, fieldName),
 				Help: helpText,
 			})
+			if entries, ok := value.(map[string]interface{}); hasValue && ok && len(entries) > 0 {
+				keys := make([]string, 0, len(entries))
+				for key := range entries {
+					keys = append(keys, key)
+				}
+				sort.Strings(keys)
+				for _, key := range keys {
+					*lines = append(*lines, fieldInfo{
+						Line: fmt.Sprintf("%s  %s: %s", indentation, key, formatScalar(entries[key])),
+						Help: "",
+					})
+				}
+				continue
+			}
 			*lines = append(*lines, fieldInfo{
 				Line: fmt.Sprintf("%s  key: value", indentation),
 				Help: "Map example",
@@ -111,16 +168,21 @@ func parseStructure(t reflect.Type, v reflect.Value, indent int, lines *[]fieldI
 
 		default:
 			// For primitive fields, we assign the default or "null" if none is provided.
-			value := defaultValue
-			if value == "" {
-				value = "null"
+			line := defaultValue
+			if secret.IsSecretField(field) {
+				// Secrets never make it into templates.
+				line = fmt.Sprintf(`"%s"`, secret.Placeholder)
+			} else if hasValue {
+				line = formatScalar(value)
+			} else if line == "" {
+				line = "null"
 			} else if field.Type.Kind() == reflect.String {
 				// If the field is a string, we enclose the value in quotes.
-				value = fmt.Sprintf(`"%s"`, value)
+				line = fmt.Sprintf(`"%s"`, line)
 			}
 
 			*lines = append(*lines, fieldInfo{
-				Line: fmt.Sprintf("%s%s: %s", indentation, fieldName, value),
+				Line: fmt.Sprintf("%s%s: %s", indentation, fieldName, line),
 				Help: helpText,
 			})
 		}
@@ -172,9 +234,54 @@ func getFieldName(field reflect.StructField) string {
 	return strings.ToLower(field.Name)
 }
 
+// getBindKey builds the Viper bind key of the field, e.g. "server.port".
+// Like Viper, it uses the mapstructure tag or the lowercase field name.
+func getBindKey(parentBindKey string, field reflect.StructField) string {
+	key := field.Tag.Get("mapstructure")
+	if key == "" {
+		key = strings.ToLower(field.Name)
+	}
+	if parentBindKey == "" {
+		return key
+	}
+	return parentBindKey + "." + key
+}
+
+// lookupValue finds the value of a bind key in a nested values map.
+// Keys are compared case-insensitively, the same way Viper does it.
+func lookupValue(values map[string]interface{}, bindKey string) (interface{}, bool) {
+	if values == nil {
+		return nil, false
+	}
+	var current interface{} = values
+	for _, key := range strings.Split(strings.ToLower(bindKey), ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return current, current != nil
+}
+
+// formatScalar renders a single value for the template, quoting strings.
+func formatScalar(value interface{}) string {
+	if str, ok := value.(string); ok {
+		return fmt.Sprintf(`"%s"`, str)
+	}
+	return fmt.Sprint(value)
+}
+
 // getDefaultValue extracts the default value from struct tags.
-// It first checks the "default" tag, then falls back to "placeholder".
-func getDefaultValue(tag reflect.StructTag) string {
+// It first checks the profile-specific "default_<profile>" tag, then falls back to "default".
+func getDefaultValue(tag reflect.StructTag, profile string) string {
+	if profile != "" {
+		if profileVal, ok := tag.Lookup("default_" + profile); ok {
+			return profileVal
+		}
+	}
 	defaultVal := tag.Get("default")
 	return defaultVal
 }
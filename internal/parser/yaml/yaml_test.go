@@ -188,3 +188,52 @@ nickname: null               # User nickname
 
 	assert.Equal(t, expected, yamlTemplate)
 }
+
+func TestGenerateProfileYAMLTemplate(t *testing.T) {
+	cfg := struct {
+		Host string `yaml:"host" default:"localhost" default_prod:"0.0.0.0"`
+		Port int    `yaml:"port" default:"8080"`
+	}{}
+	yamlTemplate := GenerateProfileYAMLTemplate(cfg, true, "prod")
+
+	expected := `# Profile: prod
+host: "0.0.0.0"
+port: 8080
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+func TestGenerateYAMLTemplateWithValues(t *testing.T) {
+	type Config struct {
+		Host    string            `mapstructure:"host" default:"localhost" help:"The hostname"`
+		Port    int               `mapstructure:"port" default:"8080" help:"The port number"`
+		Options []string          `mapstructure:"options" default:"1,2"`
+		Labels  map[string]string `mapstructure:"labels"`
+		Meta    struct {
+			Version string `mapstructure:"version" default:"1.0"`
+		} `mapstructure:"meta"`
+	}
+	values := map[string]interface{}{
+		"host":    "example.com",
+		"options": []interface{}{"a", "b", "c"},
+		"labels":  map[string]interface{}{"team": "core", "env": "prod"},
+		"meta":    map[string]interface{}{"version": "2.0"},
+	}
+	yamlTemplate := GenerateYAMLTemplateWithValues(Config{}, true, values)
+
+	expected := `host: "example.com" # The hostname
+port: 8080          # The port number
+options:
+  - a
+  - b
+  - c
+labels:
+  env: "prod"
+  team: "core"
+meta:
+  version: "2.0"
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
@@ -0,0 +1,161 @@
+package configo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// UndefinedVariableError is returned in strict interpolation mode
+// when a config value references an env var that is not set.
+var UndefinedVariableError = errors.New("undefined environment variable")
+
+// envRef is an env var referenced by a config value, e.g. `${DB_HOST:-localhost}`.
+type envRef struct {
+	Name string
+	// Default is the `:-` fallback value, if any.
+	Default string
+	// BindKey is the key of the config value that references the variable.
+	BindKey string
+}
+
+// interpolateValues expands ${VAR}, ${VAR:-default} and ${VAR:?error} references
+// in the string values of values. Unset variables expand to an empty string,
+// or fail with UndefinedVariableError if strict is set.
+//
+// Errors name the bind key but never quote the value, which may be a secret.
+func interpolateValues(values map[string]interface{}, strict bool) error {
+	return walkStrings(values, "", func(bindKey, value string) (string, error) {
+		expanded, err := expandEnv(value, func(ref envRef, op, arg string) (string, error) {
+			val, ok := os.LookupEnv(ref.Name)
+			switch op {
+			case ":-":
+				if val == "" {
+					return arg, nil
+				}
+			case ":?":
+				if val == "" {
+					if arg == "" {
+						arg = "not set"
+					}
+					return "", fmt.Errorf("%s: %s", ref.Name, arg)
+				}
+			default:
+				if !ok && strict {
+					return "", fmt.Errorf("%w %s", UndefinedVariableError, ref.Name)
+				}
+			}
+			return val, nil
+		})
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", bindKey, err)
+		}
+		return expanded, nil
+	})
+}
+
+// collectEnvRefs returns the env vars referenced by the string values of values,
+// sorted by bind key, without expanding them.
+func collectEnvRefs(values map[string]interface{}) []envRef {
+	var refs []envRef
+	_ = walkStrings(values, "", func(bindKey, value string) (string, error) {
+		return expandEnv(value, func(ref envRef, op, arg string) (string, error) {
+			ref.BindKey = bindKey
+			if op == ":-" {
+				ref.Default = arg
+			}
+			refs = append(refs, ref)
+			return "", nil
+		})
+	})
+	sort.SliceStable(refs, func(i, j int) bool { return refs[i].BindKey < refs[j].BindKey })
+	return refs
+}
+
+// walkStrings calls fn for every string value (including slice items) in values
+// and replaces the value with the result.
+func walkStrings(values map[string]interface{}, parentBindKey string, fn func(bindKey, value string) (string, error)) error {
+	for key, val := range values {
+		bindKey := key
+		if parentBindKey != "" {
+			bindKey = parentBindKey + "." + key
+		}
+		resolved, err := walkValue(val, bindKey, fn)
+		if err != nil {
+			return err
+		}
+		values[key] = resolved
+	}
+	return nil
+}
+
+func walkValue(val interface{}, bindKey string, fn func(bindKey, value string) (string, error)) (interface{}, error) {
+	switch v := val.(type) {
+	case string:
+		return fn(bindKey, v)
+	case []interface{}:
+		for i, item := range v {
+			resolved, err := walkValue(item, bindKey, fn)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = resolved
+		}
+		return v, nil
+	default:
+		if m, ok := toStringMap(val); ok {
+			return m, walkStrings(m, bindKey, fn)
+		}
+		return val, nil
+	}
+}
+
+// expandEnv replaces every ${...} reference in s with the result of lookup,
+// which receives the reference, its operator (":-", ":?" or "") and the
+// operator argument. `$${` is an escaped, literal `${`.
+func expandEnv(s string, lookup func(ref envRef, op, arg string) (string, error)) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+
+	var sb strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			sb.WriteString(s)
+			return sb.String(), nil
+		}
+		if start > 0 && s[start-1] == '$' {
+			sb.WriteString(s[:start-1] + "${")
+			s = s[start+2:]
+			continue
+		}
+		end := strings.Index(s[start:], "}")
+		if end < 0 {
+			return "", errors.New("unterminated variable reference")
+		}
+		end += start
+
+		expr := s[start+2 : end]
+		name, op, arg := expr, "", ""
+		for _, candidate := range []string{":-", ":?"} {
+			if idx := strings.Index(expr, candidate); idx >= 0 {
+				name, op, arg = expr[:idx], candidate, expr[idx+2:]
+				break
+			}
+		}
+		if name == "" {
+			return "", errors.New("empty variable name")
+		}
+
+		val, err := lookup(envRef{Name: name}, op, arg)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(s[:start])
+		sb.WriteString(val)
+		s = s[end+1:]
+	}
+}
@@ -0,0 +1,67 @@
+package configo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolateValues(t *testing.T) {
+	t.Setenv("CONFIGO_TEST_HOST", "dbhost")
+	t.Setenv("CONFIGO_TEST_EMPTY", "")
+
+	values := map[string]interface{}{
+		"url":     "postgres://${CONFIGO_TEST_HOST}:5432/app",
+		"port":    "${CONFIGO_TEST_PORT:-5432}",
+		"empty":   "${CONFIGO_TEST_EMPTY:-fallback}",
+		"unset":   "[${CONFIGO_TEST_UNSET}]",
+		"escaped": "$${CONFIGO_TEST_HOST}",
+		"nested":  map[string]interface{}{"hosts": []interface{}{"${CONFIGO_TEST_HOST}", 1}},
+		"number":  8080,
+	}
+	require.NoError(t, interpolateValues(values, false))
+
+	expected := map[string]interface{}{
+		"url":     "postgres://dbhost:5432/app",
+		"port":    "5432",
+		"empty":   "fallback",
+		"unset":   "[]",
+		"escaped": "${CONFIGO_TEST_HOST}",
+		"nested":  map[string]interface{}{"hosts": []interface{}{"dbhost", 1}},
+		"number":  8080,
+	}
+	assert.Equal(t, expected, values)
+}
+
+func TestInterpolateValues_Errors(t *testing.T) {
+	err := interpolateValues(map[string]interface{}{"url": "${CONFIGO_TEST_UNSET}"}, true)
+	assert.True(t, errors.Is(err, UndefinedVariableError), "got %v", err)
+
+	err = interpolateValues(map[string]interface{}{"url": "${CONFIGO_TEST_UNSET:?url is required}"}, false)
+	assert.ErrorContains(t, err, "url is required")
+
+	err = interpolateValues(map[string]interface{}{"url": "${CONFIGO_TEST_UNSET"}, false)
+	assert.Error(t, err)
+
+	// Значение, которое может быть секретом, не цитируется в ошибке
+	err = interpolateValues(map[string]interface{}{"db": map[string]interface{}{"password": "s3cr${et"}}, false)
+	assert.EqualError(t, err, "db.password: unterminated variable reference")
+	err = interpolateValues(map[string]interface{}{"password": "s3cr${}et"}, false)
+	assert.EqualError(t, err, "password: empty variable name")
+}
+
+func TestCollectEnvRefs(t *testing.T) {
+	values := map[string]interface{}{
+		"server": map[string]interface{}{"host": "${HOST:-localhost}"},
+		"db":     map[string]interface{}{"url": "postgres://${DB_USER}@${DB_HOST:?required}"},
+	}
+
+	expected := []envRef{
+		{Name: "DB_USER", BindKey: "db.url"},
+		{Name: "DB_HOST", BindKey: "db.url"},
+		{Name: "HOST", Default: "localhost", BindKey: "server.host"},
+	}
+	assert.Equal(t, expected, collectEnvRefs(values))
+}
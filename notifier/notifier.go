@@ -2,7 +2,14 @@ package notifier
 
 import (
 	"context"
+	"fmt"
+	"reflect"
+	"strings"
 	"sync"
+	"time"
+
+	"github.com/vsysa/configo/internal/parser/diff"
+	"github.com/vsysa/configo/internal/parser/secret"
 )
 
 // ConfigUpdateMsg представляет сообщение об обновлении конфигурации,
@@ -10,29 +17,139 @@ import (
 type ConfigUpdateMsg[T any] struct {
 	OldConfig T
 	NewConfig T
+	// Profile - активный профиль конфигурации (пустой, если профиль не задан).
+	Profile string
+	// Changes - список измененных полей в порядке их объявления в структуре.
+	Changes []Change
+	// Version - версия новой конфигурации. Начальная загрузка имеет версию 1,
+	// каждая успешная перезагрузка увеличивает ее на единицу.
+	Version uint64
+	// Timestamp - время применения новой конфигурации.
+	Timestamp time.Time
+	// Source - причина перезагрузки.
+	Source UpdateSource
+	// Checksum - SHA-256 (hex) загруженных значений новой конфигурации.
+	Checksum string
+}
+
+// UpdateSource описывает, что вызвало перезагрузку конфигурации.
+type UpdateSource string
+
+const (
+	// UpdateSourceFile - изменение файла конфигурации или файла секрета.
+	UpdateSourceFile UpdateSource = "file"
+	// UpdateSourceWatch - изменение, о котором сообщил Watch пользовательского источника.
+	UpdateSourceWatch UpdateSource = "watch"
+	// UpdateSourceReload - ручная перезагрузка через Reload.
+	UpdateSourceReload UpdateSource = "reload"
+	// UpdateSourceSignal - получен сигнал перезагрузки (WithReloadOnSignal).
+	UpdateSourceSignal UpdateSource = "signal"
+)
+
+// Change описывает изменение одного поля конфигурации. Path - bind key поля
+// (как в env.GetEnvs, например "server.port"), Old и New - значения до и после
+// изменения. Значения секретных полей маскируются.
+type Change struct {
+	Path string
+	Old  interface{}
+	New  interface{}
+}
+
+// Diff сравнивает две конфигурации и возвращает измененные поля в порядке их
+// объявления в структуре. Вложенные структуры сравниваются по полям, остальные
+// поля (включая срезы и map) - целиком.
+func Diff[T any](oldConfig, newConfig T) []Change {
+	var changes []Change
+	for _, change := range diff.Diff(oldConfig, newConfig) {
+		changes = append(changes, Change{Path: change.Path, Old: change.Old, New: change.New})
+	}
+	return changes
+}
+
+// Changed сообщает, изменилось ли поле с указанным bind key или любое вложенное
+// в него поле: Changed("server") вернет true при изменении "server.port".
+// Регистр не учитывается.
+func (m ConfigUpdateMsg[T]) Changed(path string) bool {
+	path = strings.ToLower(path)
+	for _, change := range m.Changes {
+		changePath := strings.ToLower(change.Path)
+		if changePath == path || strings.HasPrefix(changePath, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// String форматирует сообщение для логов. Секретные поля конфигураций
+// (тег `secret:"true"` и тип configo.Secret) маскируются.
+func (m ConfigUpdateMsg[T]) String() string {
+	out := fmt.Sprintf("config updated: old=%+v new=%+v", secret.Redact(m.OldConfig), secret.Redact(m.NewConfig))
+	if m.Profile != "" {
+		out += fmt.Sprintf(" profile=%s", m.Profile)
+	}
+	if m.Version != 0 {
+		out += fmt.Sprintf(" version=%d", m.Version)
+	}
+	if m.Source != "" {
+		out += fmt.Sprintf(" source=%s", m.Source)
+	}
+	return out
 }
 
 type ConfigUpdateNotifier[T any] struct {
-	mu          sync.RWMutex
-	subscribers map[chan ConfigUpdateMsg[T]]struct{}
+	// forceUpdates отправляет объединенные события, даже если они ничего не меняют.
+	forceUpdates bool
+	mu           sync.RWMutex
+	subscribers  map[chan ConfigUpdateMsg[T]]struct{}
+	// done закрывается в Close, wg ожидает горутины подписок.
+	done   chan struct{}
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NotifierOption настраивает ConfigUpdateNotifier.
+type NotifierOption[T any] func(*ConfigUpdateNotifier[T])
+
+// WithForceUpdates отправляет объединенное событие, даже если итоговая
+// конфигурация совпадает со старой (например, после изменений A→B→A).
+func WithForceUpdates[T any]() NotifierOption[T] {
+	return func(r *ConfigUpdateNotifier[T]) {
+		r.forceUpdates = true
+	}
 }
 
 // NewEventBus создает новый eventBus.
-func NewConfigUpdateNotifier[T any]() *ConfigUpdateNotifier[T] {
-	return &ConfigUpdateNotifier[T]{
+func NewConfigUpdateNotifier[T any](opts ...NotifierOption[T]) *ConfigUpdateNotifier[T] {
+	r := &ConfigUpdateNotifier[T]{
 		subscribers: make(map[chan ConfigUpdateMsg[T]]struct{}),
+		done:        make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
+	return r
 }
 
 // Subscribe позволяет подписчику получать события. Возвращает канал, через который будут получены события.
 func (r *ConfigUpdateNotifier[T]) Subscribe(ctx context.Context) <-chan ConfigUpdateMsg[T] {
 	ch := make(chan ConfigUpdateMsg[T], 1) // Используем буферизированный канал для предотвращения блокировки
 	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// После Close подписчик сразу получает закрытый канал
+	if r.closed {
+		close(ch)
+		return ch
+	}
 	r.subscribers[ch] = struct{}{}
-	r.mu.Unlock()
 
+	r.wg.Add(1)
 	go func() {
-		<-ctx.Done()
+		defer r.wg.Done()
+		select {
+		case <-ctx.Done():
+		case <-r.done:
+		}
 		r.mu.Lock()
 		delete(r.subscribers, ch)
 		close(ch)
@@ -42,15 +159,53 @@ func (r *ConfigUpdateNotifier[T]) Subscribe(ctx context.Context) <-chan ConfigUp
 	return ch
 }
 
-// Publish публикует событие всем подписчикам.
+// Close закрывает каналы всех подписчиков и дожидается завершения их горутин.
+// Повторный вызов Close ничего не делает.
+func (r *ConfigUpdateNotifier[T]) Close() {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return
+	}
+	r.closed = true
+	close(r.done)
+	r.mu.Unlock()
+
+	r.wg.Wait()
+}
+
+// NewEvent публикует событие всем подписчикам. Если подписчик еще не прочитал
+// предыдущее событие, оно заменяется объединенным: со старой конфигурацией из
+// предыдущего события и новой из текущего, чтобы подписчик не пропустил
+// последнюю версию конфигурации. Если в итоге конфигурация не изменилась,
+// объединенное событие не отправляется (кроме режима WithForceUpdates).
 func (r *ConfigUpdateNotifier[T]) NewEvent(msg ConfigUpdateMsg[T]) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	if r.closed {
+		return
+	}
 	for ch := range r.subscribers {
 		select {
 		case ch <- msg: // Отправляем событие, если канал готов принять сообщение
-		default: // Пропускаем, если в канале уже есть сообщение
+			continue
+		default:
+		}
+
+		merged := msg
+		select {
+		case pending := <-ch:
+			merged.OldConfig = pending.OldConfig
+			merged.Changes = Diff(pending.OldConfig, msg.NewConfig)
+			if !r.forceUpdates && reflect.DeepEqual(merged.OldConfig, merged.NewConfig) {
+				continue
+			}
+		default: // Подписчик успел прочитать предыдущее событие
+		}
+		select {
+		case ch <- merged:
+		default:
 		}
 	}
 }
@@ -108,3 +108,84 @@ func TestConfigUpdateNotifier_MultipleSubscribers(t *testing.T) {
 
 	wg.Wait()
 }
+
+// Тест на закрытие всех подписок через Close
+func TestConfigUpdateNotifier_Close(t *testing.T) {
+	notifier := NewConfigUpdateNotifier[MockConfig]()
+
+	subscriber := notifier.Subscribe(context.Background())
+	notifier.Close()
+
+	// Канал закрыт сразу после Close, так как Close дожидается горутин подписок
+	_, ok := <-subscriber
+	assert.False(t, ok, "channel should be closed")
+
+	// Публикация и подписка после Close безопасны
+	notifier.NewEvent(ConfigUpdateMsg[MockConfig]{})
+	_, ok = <-notifier.Subscribe(context.Background())
+	assert.False(t, ok, "channel should be closed")
+
+	// Повторный Close ничего не делает
+	notifier.Close()
+}
+
+// Тест на проверку измененных полей
+func TestConfigUpdateMsg_Changed(t *testing.T) {
+	msg := ConfigUpdateMsg[MockConfig]{Changes: []Change{
+		{Path: "server.port", Old: 8080, New: 9090},
+		{Path: "tags", Old: []string{"a"}, New: []string{"b"}},
+	}}
+
+	assert.True(t, msg.Changed("server.port"))
+	assert.True(t, msg.Changed("Server.Port"))
+	assert.True(t, msg.Changed("server"))
+	assert.True(t, msg.Changed("tags"))
+	assert.False(t, msg.Changed("server.host"))
+	assert.False(t, msg.Changed("serv"))
+	assert.False(t, ConfigUpdateMsg[MockConfig]{}.Changed("server"))
+}
+
+// Тест на объединение событий, которые подписчик не успел прочитать
+func TestConfigUpdateNotifier_MergePending(t *testing.T) {
+	notifier := NewConfigUpdateNotifier[MockConfig]()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	subscriber := notifier.Subscribe(ctx)
+	notifier.NewEvent(ConfigUpdateMsg[MockConfig]{OldConfig: MockConfig{Value: "v1"}, NewConfig: MockConfig{Value: "v2"}, Version: 2})
+	notifier.NewEvent(ConfigUpdateMsg[MockConfig]{OldConfig: MockConfig{Value: "v2"}, NewConfig: MockConfig{Value: "v3"}, Version: 3})
+
+	msg := <-subscriber
+	assert.Equal(t, "v1", msg.OldConfig.Value)
+	assert.Equal(t, "v3", msg.NewConfig.Value)
+	assert.Equal(t, uint64(3), msg.Version)
+	assert.Equal(t, []Change{{Path: "value", Old: "v1", New: "v3"}}, msg.Changes)
+}
+
+// Тест на отбрасывание объединенного события, которое ничего не меняет (A→B→A)
+func TestConfigUpdateNotifier_MergeNoChange(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	notifier := NewConfigUpdateNotifier[MockConfig]()
+	subscriber := notifier.Subscribe(ctx)
+	notifier.NewEvent(ConfigUpdateMsg[MockConfig]{OldConfig: MockConfig{Value: "v1"}, NewConfig: MockConfig{Value: "v2"}, Version: 2})
+	notifier.NewEvent(ConfigUpdateMsg[MockConfig]{OldConfig: MockConfig{Value: "v2"}, NewConfig: MockConfig{Value: "v1"}, Version: 3})
+
+	select {
+	case msg := <-subscriber:
+		t.Errorf("Unexpected message: %v", msg)
+	default:
+	}
+
+	// С WithForceUpdates объединенное событие отправляется
+	notifier = NewConfigUpdateNotifier[MockConfig](WithForceUpdates[MockConfig]())
+	subscriber = notifier.Subscribe(ctx)
+	notifier.NewEvent(ConfigUpdateMsg[MockConfig]{OldConfig: MockConfig{Value: "v1"}, NewConfig: MockConfig{Value: "v2"}, Version: 2})
+	notifier.NewEvent(ConfigUpdateMsg[MockConfig]{OldConfig: MockConfig{Value: "v2"}, NewConfig: MockConfig{Value: "v1"}, Version: 3})
+
+	msg := <-subscriber
+	assert.Equal(t, "v1", msg.OldConfig.Value)
+	assert.Equal(t, "v1", msg.NewConfig.Value)
+	assert.Empty(t, msg.Changes)
+}
@@ -1,10 +1,31 @@
 package configo
 
+import (
+	"flag"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+)
+
 type Option[T any] func(*ConfigManager[T])
 
 func WithConfigFilePath[T any](path string) Option[T] {
 	return func(cm *ConfigManager[T]) {
-		cm.configFilePath = path
+		cm.configFiles = []string{path}
+	}
+}
+
+// WithConfigFiles sets a stack of config files that are deep-merged in the given
+// order: values from later files override values from earlier ones, and env vars
+// still take precedence over all of them. Every file of the stack is watched,
+// and the merged result is reloaded when any of them changes.
+//
+//	configo.WithConfigFiles[AppConfig]("base.yml", "prod.yml", "local.yml")
+func WithConfigFiles[T any](paths ...string) Option[T] {
+	return func(cm *ConfigManager[T]) {
+		cm.configFiles = paths
 	}
 }
 
@@ -13,3 +34,215 @@ func WithErrorHandler[T any](handler func(error)) Option[T] {
 		cm.errorHandler = handler
 	}
 }
+
+// WithConfigFormat sets the format of the config files (FormatYAML, FormatJSON,
+// FormatTOML or FormatDotenv) when it cannot be detected from the file extension,
+// e.g. for a file named `config`.
+func WithConfigFormat[T any](format Format) Option[T] {
+	return func(cm *ConfigManager[T]) {
+		cm.configFormat = format
+	}
+}
+
+// WithConfigDir adds a directory of config fragments, given as a glob pattern
+// (e.g. "/etc/app/conf.d/*.yml"). Matching files are merged in lexical order
+// on top of the config files, and the directory is watched, so adding, removing
+// or editing a fragment reloads the configuration.
+//
+// Use WithConfigFiles[T]() without arguments to load from the directory only.
+func WithConfigDir[T any](pattern string) Option[T] {
+	return func(cm *ConfigManager[T]) {
+		cm.configDirs = append(cm.configDirs, pattern)
+	}
+}
+
+// WithProfile selects the active profile, e.g. "prod". Every config file is then
+// overlaid with its profile counterpart (config.yml -> config.prod.yml) when it exists,
+// and `default_<profile>` tags take precedence over `default` tags.
+//
+// The profile env var (CONFIGO_PROFILE by default), if set, overrides this option.
+func WithProfile[T any](profile string) Option[T] {
+	return func(cm *ConfigManager[T]) {
+		cm.profile = profile
+	}
+}
+
+// WithProfileEnvVar changes the env var that selects the active profile.
+// An empty name disables profile selection through the environment.
+func WithProfileEnvVar[T any](name string) Option[T] {
+	return func(cm *ConfigManager[T]) {
+		cm.profileEnvVar = name
+	}
+}
+
+// WithSources replaces the default source stack (`default` tags, config files,
+// env vars) with the given sources. Sources are merged in the given order:
+// values from later sources override values from earlier ones.
+//
+//	configo.WithSources[AppConfig](
+//		configo.NewDefaultsSource[AppConfig](),
+//		configo.NewFileSource("config.yml"),
+//		mySource,
+//		configo.NewEnvSource[AppConfig](),
+//	)
+//
+// Options that configure the default stack (WithConfigFilePath, WithConfigDir, ...)
+// have no effect together with WithSources.
+func WithSources[T any](sources ...Source) Option[T] {
+	return func(cm *ConfigManager[T]) {
+		cm.sources = sources
+	}
+}
+
+// WithDotenvFiles loads the given .env files as an env layer: their variables
+// use the env var names of T and are overridden by the real environment.
+// Later files override earlier ones. Missing files are skipped, and edits to
+// the files are hot-reloaded like config file edits.
+func WithDotenvFiles[T any](paths ...string) Option[T] {
+	return func(cm *ConfigManager[T]) {
+		cm.dotenvFiles = append(cm.dotenvFiles, paths...)
+	}
+}
+
+// WithStrictInterpolation makes loading fail with UndefinedVariableError when a
+// value in a config file references an env var that is not set, e.g. `${DB_HOST}`.
+// By default such references expand to an empty string.
+func WithStrictInterpolation[T any]() Option[T] {
+	return func(cm *ConfigManager[T]) {
+		cm.strictInterpolation = true
+	}
+}
+
+// WithoutFileRefs takes the `file:<path>` values of the given bind keys
+// (e.g. "database.url" holding a SQLite DSN `file:test.db`) in config files
+// literally instead of reading them from a file. Without bind keys, no value
+// of a config file is read from a file; _FILE env vars still are.
+func WithoutFileRefs[T any](bindKeys ...string) Option[T] {
+	return func(cm *ConfigManager[T]) {
+		if len(bindKeys) == 0 {
+			cm.fileRefsDisabled = true
+		}
+		for _, bindKey := range bindKeys {
+			cm.literalBindKeys[strings.ToLower(bindKey)] = struct{}{}
+		}
+	}
+}
+
+// WithDebounce sets how long the manager waits after a change event for
+// further events before reloading (DefaultDebounce by default). A burst of
+// events, e.g. the several writes of a single save, results in one reload and
+// one ConfigUpdateMsg. Zero disables the wait; events that arrive during a
+// reload are still coalesced into a single follow-up reload.
+func WithDebounce[T any](window time.Duration) Option[T] {
+	return func(cm *ConfigManager[T]) {
+		cm.debounce = window
+	}
+}
+
+// WithForceUpdates publishes a ConfigUpdateMsg after every successful reload.
+// By default a reload that decodes to the same config as the current one, e.g.
+// after a file was touched or rewritten with identical content, is not published.
+func WithForceUpdates[T any]() Option[T] {
+	return func(cm *ConfigManager[T]) {
+		cm.forceUpdates = true
+	}
+}
+
+// WithPollInterval watches the config files, included files, dotenv files and
+// secret files by polling them every interval instead of using fsnotify, for
+// NFS, overlay filesystems and Kubernetes ConfigMap symlink swaps. Files are
+// stat'ed through their symlinks and hashed, so the config is reloaded only
+// if the content actually changed.
+func WithPollInterval[T any](interval time.Duration) Option[T] {
+	return func(cm *ConfigManager[T]) {
+		cm.pollInterval = interval
+	}
+}
+
+// WithReloadOnSignal reloads the config whenever the process receives one of
+// the given signals, e.g. syscall.SIGHUP, in addition to file changes. This
+// lets deploy tooling trigger reloads, and helps on filesystems where file
+// change notifications are unreliable.
+func WithReloadOnSignal[T any](signals ...os.Signal) Option[T] {
+	return func(cm *ConfigManager[T]) {
+		cm.reloadSignals = append(cm.reloadSignals, signals...)
+	}
+}
+
+// WithFlags parses args (usually os.Args[1:]) as command-line flags generated
+// from the struct tags of T, see NewFlagSet. Flags that are set take precedence
+// over env vars and config files. A parsing error, including flag.ErrHelp for
+// -h, is returned by NewConfigManager.
+func WithFlags[T any](args []string) Option[T] {
+	return func(cm *ConfigManager[T]) {
+		if args == nil {
+			args = []string{}
+		}
+		cm.flagArgs = args
+	}
+}
+
+// WithFlagSet layers an already parsed flag set created with NewFlagSet
+// on top of env vars and config files. Use it instead of WithFlags when
+// the application registers flags of its own.
+func WithFlagSet[T any](flagSet *flag.FlagSet) Option[T] {
+	return func(cm *ConfigManager[T]) {
+		cm.flagSet = flagSet
+	}
+}
+
+// WithOptionalConfigFile tolerates missing config files. Instead of failing, the
+// manager starts from `default` tags and env vars, and hot-applies a config file
+// as soon as it appears.
+func WithOptionalConfigFile[T any]() Option[T] {
+	return func(cm *ConfigManager[T]) {
+		cm.optionalConfigFiles = true
+	}
+}
+
+// WithWaitForFile makes NewConfigManager wait up to timeout for the config to
+// become loadable, i.e. for the config file to appear, parse and validate,
+// instead of failing on the first attempt. This is useful when a Kubernetes
+// ConfigMap volume is mounted a few seconds after the container starts.
+func WithWaitForFile[T any](timeout time.Duration) Option[T] {
+	return func(cm *ConfigManager[T]) {
+		cm.waitForFile = timeout
+	}
+}
+
+// WithReader reads the config document from r in the given format instead of
+// the config file. The reader is consumed once; `default` tags, env vars and
+// validation are applied as usual, but there is no hot reload.
+func WithReader[T any](format Format, r io.Reader) Option[T] {
+	return func(cm *ConfigManager[T]) {
+		cm.addDocument(newDocument[T](NewReaderSource(format, r)))
+	}
+}
+
+// WithBytes decodes the config document from data in the given format instead
+// of the config file, e.g. a default config embedded with //go:embed.
+// There is no hot reload for it.
+func WithBytes[T any](format Format, data []byte) Option[T] {
+	return func(cm *ConfigManager[T]) {
+		cm.addDocument(newDocument[T](NewBytesSource(format, data)))
+	}
+}
+
+// WithFS reads the config file at path in fsys (e.g. an embed.FS) instead of
+// the config file on disk. The format is detected from the file extension.
+// There is no hot reload for it.
+func WithFS[T any](fsys fs.FS, path string) Option[T] {
+	return func(cm *ConfigManager[T]) {
+		cm.addDocument(newDocument[T](NewFSSource(fsys, path)))
+	}
+}
+
+// WithEmbeddedDefaults registers a base config document at path in fsys (usually
+// a defaults.yml embedded with //go:embed). It is the lowest-precedence document:
+// it overrides the `default` tags and is overridden by the config files and env vars.
+// See GenerateYAMLTemplateFromFS to render it as a template.
+func WithEmbeddedDefaults[T any](fsys fs.FS, path string) Option[T] {
+	return func(cm *ConfigManager[T]) {
+		cm.baseDocuments = append(cm.baseDocuments, newDocument[T](NewFSSource(fsys, path)))
+	}
+}
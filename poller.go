@@ -0,0 +1,140 @@
+package configo
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// fileSource is implemented by sources that read local files. Instead of
+// calling Watch, ConfigManager registers their files with its shared
+// fileWatcher, or polls them in polling mode.
+type fileSource interface {
+	watchedFiles() []string
+	watch(w *fileWatcher) error
+}
+
+func (s *FileSource) watchedFiles() []string {
+	s.includes.mu.Lock()
+	defer s.includes.mu.Unlock()
+	return append([]string{s.path}, s.includes.included...)
+}
+
+func (s *DirSource) watchedFiles() []string {
+	matches, _ := filepath.Glob(s.pattern)
+	sort.Strings(matches)
+
+	s.includes.mu.Lock()
+	defer s.includes.mu.Unlock()
+	return append(matches, s.includes.included...)
+}
+
+func (s *DotenvSource[T]) watchedFiles() []string {
+	return []string{s.path}
+}
+
+// fileState is the last polled state of a file.
+type fileState struct {
+	// realPath is the file the path resolves to through symlinks,
+	// e.g. the current ..data directory of a Kubernetes ConfigMap.
+	realPath string
+	size     int64
+	modTime  time.Time
+	hash     [sha256.Size]byte
+}
+
+// filePoller detects file changes by polling, for filesystems where fsnotify
+// is unreliable (NFS, overlayfs) and for Kubernetes ConfigMap symlink swaps.
+// Files are stat'ed on every poll and hashed only if their stat changed,
+// so that a change is reported only if the content actually differs.
+type filePoller struct {
+	interval time.Duration
+	// files returns the files to poll; it is called on every poll,
+	// since includes, conf.d fragments and secret files can change.
+	files  func() []string
+	states map[string]fileState
+}
+
+func newFilePoller(interval time.Duration, files func() []string) *filePoller {
+	p := &filePoller{
+		interval: interval,
+		files:    files,
+		states:   make(map[string]fileState),
+	}
+	p.poll()
+	return p
+}
+
+// Run polls the files until ctx is done, calling onChange whenever
+// the content of a file has changed, or a file appeared or disappeared.
+func (p *filePoller) Run(ctx context.Context, onChange func()) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if p.poll() {
+				onChange()
+			}
+		}
+	}
+}
+
+// poll updates the states of the files and reports whether any of them changed.
+func (p *filePoller) poll() bool {
+	changed := false
+	states := make(map[string]fileState, len(p.states))
+	for _, file := range p.files() {
+		if _, ok := states[file]; ok {
+			continue
+		}
+		prev, existed := p.states[file]
+		state, exists := p.stat(file, prev, existed)
+		if exists {
+			states[file] = state
+		}
+		if exists != existed || (exists && state.hash != prev.hash) {
+			changed = true
+		}
+	}
+	// A file that is no longer polled (e.g. removed from conf.d) is a change as well.
+	for file := range p.states {
+		if _, ok := states[file]; !ok {
+			changed = true
+		}
+	}
+	p.states = states
+	return changed
+}
+
+// stat returns the current state of the file, reusing the previous hash
+// if the resolved path, size and modification time did not change.
+func (p *filePoller) stat(file string, prev fileState, existed bool) (fileState, bool) {
+	realPath, err := filepath.EvalSymlinks(file)
+	if err != nil {
+		return fileState{}, false
+	}
+	info, err := os.Stat(realPath)
+	if err != nil || info.IsDir() {
+		return fileState{}, false
+	}
+
+	state := fileState{realPath: realPath, size: info.Size(), modTime: info.ModTime()}
+	if existed && prev.realPath == state.realPath && prev.size == state.size && prev.modTime.Equal(state.modTime) {
+		state.hash = prev.hash
+		return state, true
+	}
+
+	data, err := os.ReadFile(realPath)
+	if err != nil {
+		return fileState{}, false
+	}
+	state.hash = sha256.Sum256(data)
+	return state, true
+}
@@ -0,0 +1,219 @@
+package configo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vsysa/configo/internal/parser/defaultValues"
+	"github.com/vsysa/configo/internal/parser/env"
+	"github.com/vsysa/configo/internal/parser/flags"
+	"github.com/vsysa/configo/internal/parser/secret"
+	"gopkg.in/yaml.v3"
+)
+
+// Origin describes a value provided by one source.
+type Origin struct {
+	// Source names the source, e.g. "file config.yml", "env" or "defaults".
+	Source string
+	// Location pinpoints the value within the source, e.g. "config.yml:12",
+	// "SERVER_PORT" or "-server.port". It is empty if the source cannot tell.
+	Location string
+	// Value is the value as provided by the source, before interpolation.
+	Value interface{}
+}
+
+func (o Origin) String() string {
+	if o.Location == "" {
+		return o.Source
+	}
+	return o.Source + " (" + o.Location + ")"
+}
+
+// Provenance explains where the value of a bind key came from.
+type Provenance struct {
+	BindKey string
+	// Value is the effective value, after interpolation and file references.
+	Value interface{}
+	// Origin is the source whose value won. It is zero if no source sets the key.
+	Origin Origin
+	// Overridden are the values of lower-precedence sources the winning value
+	// overrode, highest precedence first.
+	Overridden []Origin
+}
+
+// locator is implemented by sources that can tell where they define a value.
+// loadLocated loads the values like Load and also returns the location of
+// each lowercased bind key, e.g. "config.yml:12", "SERVER_PORT" or "-server.port".
+type locator interface {
+	loadLocated(ctx context.Context) (map[string]interface{}, map[string]string, error)
+}
+
+// loadSource loads a source, with the locations of its values if it is a locator.
+func loadSource(ctx context.Context, source Source) (map[string]interface{}, map[string]string, error) {
+	if l, ok := source.(locator); ok {
+		return l.loadLocated(ctx)
+	}
+	values, err := source.Load(ctx)
+	return values, nil, err
+}
+
+// sourceLayer is the values a source provided in the last load, with the
+// locations recorded while loading them.
+type sourceLayer struct {
+	source    Source
+	values    map[string]interface{}
+	locations map[string]string
+}
+
+// Explain returns the provenance of every bind key of T, in struct order.
+func (r *ConfigManager[T]) Explain() []Provenance {
+	r.updateMu.RLock()
+	layers, values := r.layers, r.values
+	r.updateMu.RUnlock()
+
+	var configStruct T
+	bindKeys := configBindKeys(configStruct)
+	secrets := secretBindKeys(configStruct)
+	out := make([]Provenance, 0, len(bindKeys))
+	for _, bindKey := range bindKeys {
+		out = append(out, explain(layers, values, bindKey, secrets))
+	}
+	return out
+}
+
+// Provenance returns the provenance of a single bind key, e.g. "server.port".
+// It reports false if no source sets the key.
+func (r *ConfigManager[T]) Provenance(bindKey string) (Provenance, bool) {
+	r.updateMu.RLock()
+	layers, values := r.layers, r.values
+	r.updateMu.RUnlock()
+
+	var configStruct T
+	p := explain(layers, values, bindKey, secretBindKeys(configStruct))
+	return p, p.Origin.Source != ""
+}
+
+// explain builds the provenance of bindKey. Values of secret keys are masked.
+func explain(layers []sourceLayer, values map[string]interface{}, bindKey string, secrets map[string]struct{}) Provenance {
+	_, isSecret := secrets[strings.ToLower(bindKey)]
+	mask := func(value interface{}) interface{} {
+		if isSecret && value != nil {
+			return MaskedValue
+		}
+		return value
+	}
+
+	p := Provenance{BindKey: bindKey}
+	p.Value, _ = lookupValue(values, bindKey)
+	p.Value = mask(p.Value)
+
+	for i := len(layers) - 1; i >= 0; i-- {
+		value, ok := lookupValue(layers[i].values, bindKey)
+		if !ok {
+			continue
+		}
+		origin := Origin{
+			Source:   sourceName(layers[i].source),
+			Location: layers[i].locations[strings.ToLower(bindKey)],
+			Value:    mask(value),
+		}
+		if p.Origin.Source == "" {
+			p.Origin = origin
+		} else {
+			p.Overridden = append(p.Overridden, origin)
+		}
+	}
+	return p
+}
+
+// configBindKeys lists the bind keys of cfg known to the env, default and flag parsers.
+func configBindKeys(cfg interface{}) []string {
+	var bindKeys []string
+	seen := make(map[string]struct{})
+	add := func(bindKey string) {
+		if _, ok := seen[bindKey]; !ok {
+			seen[bindKey] = struct{}{}
+			bindKeys = append(bindKeys, bindKey)
+		}
+	}
+
+	for _, info := range env.GetEnvs(cfg) {
+		add(info.BindKey)
+	}
+	if defaults, err := defaultValues.GetDefaultValues(cfg); err == nil {
+		for _, info := range defaults {
+			add(info.BindKey)
+		}
+	}
+	for _, info := range flags.GetFlags(cfg) {
+		add(info.BindKey)
+	}
+	return bindKeys
+}
+
+// secretBindKeys returns the lowercased bind keys of the secret fields of cfg.
+func secretBindKeys(cfg interface{}) map[string]struct{} {
+	out := make(map[string]struct{})
+	for _, bindKey := range secret.BindKeys(cfg) {
+		out[strings.ToLower(bindKey)] = struct{}{}
+	}
+	return out
+}
+
+func sourceName(source Source) string {
+	if s, ok := source.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", source)
+}
+
+// fileLocations returns the location of every key of the values of a config
+// file, with the line number for YAML files, e.g. "config.yml:12".
+func fileLocations(path string, format Format, data []byte, values map[string]interface{}) map[string]string {
+	lines := make(map[string]int)
+	if format == FormatYAML {
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err == nil && len(doc.Content) > 0 {
+			yamlKeyLines(doc.Content[0], "", lines)
+		}
+	}
+
+	locations := make(map[string]string)
+	walkKeys(values, "", func(bindKey string) {
+		if line := lines[bindKey]; line > 0 {
+			locations[bindKey] = fmt.Sprintf("%s:%d", path, line)
+		} else {
+			locations[bindKey] = path
+		}
+	})
+	return locations
+}
+
+// yamlKeyLines records the line of every key of a YAML mapping node,
+// by lowercased bind key.
+func yamlKeyLines(node *yaml.Node, prefix string, lines map[string]int) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		bindKey := prefix + strings.ToLower(node.Content[i].Value)
+		lines[bindKey] = node.Content[i].Line
+		yamlKeyLines(node.Content[i+1], bindKey+".", lines)
+	}
+}
+
+// walkKeys calls fn with the lowercased bind key of every non-nil value,
+// including nested maps.
+func walkKeys(values map[string]interface{}, prefix string, fn func(bindKey string)) {
+	for key, value := range values {
+		if value == nil {
+			continue
+		}
+		bindKey := prefix + strings.ToLower(key)
+		fn(bindKey)
+		if nested, ok := toStringMap(value); ok {
+			walkKeys(nested, bindKey+".", fn)
+		}
+	}
+}
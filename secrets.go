@@ -0,0 +1,169 @@
+package configo
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/vsysa/configo/internal/parser/secret"
+)
+
+const (
+	// FileRefPrefix marks a config value that is read from a file,
+	// e.g. `password: file:/run/secrets/db_password`. It is only recognized
+	// in config files; WithoutFileRefs takes such values literally.
+	FileRefPrefix = "file:"
+	// EnvFileSuffix is appended to an env var name to read its value from a file,
+	// e.g. DATABASE_PASSWORD_FILE=/run/secrets/db_password.
+	EnvFileSuffix = "_FILE"
+)
+
+// fileRef is a value that is read from the file at the given path once the
+// values of all sources have been merged, so that a reference overridden by
+// a higher layer is never read.
+type fileRef string
+
+func (f fileRef) String() string {
+	return FileRefPrefix + string(f)
+}
+
+// lookupEnvFile returns a file reference for the env var if it is unset
+// and its _FILE counterpart points to a file, the way Docker images do it.
+func lookupEnvFile(lookup func(string) (string, bool), envVar string) (fileRef, bool) {
+	path, ok := lookup(envVar + EnvFileSuffix)
+	if !ok || path == "" {
+		return "", false
+	}
+	return fileRef(path), true
+}
+
+// markFileRefs turns the `file:` values in the values of a config document
+// into file references, except for the given lowercased bind keys, whose
+// values (e.g. a SQLite DSN like `file:test.db?cache=shared`) are kept as is.
+func markFileRefs(values map[string]interface{}, parentBindKey string, literal map[string]struct{}) {
+	for key, val := range values {
+		bindKey := strings.ToLower(key)
+		if parentBindKey != "" {
+			bindKey = parentBindKey + "." + bindKey
+		}
+		if _, ok := literal[bindKey]; ok {
+			continue
+		}
+		if str, ok := val.(string); ok {
+			if path, ok := strings.CutPrefix(str, FileRefPrefix); ok {
+				values[key] = fileRef(path)
+			}
+		} else if m, ok := toStringMap(val); ok {
+			markFileRefs(m, bindKey, literal)
+			values[key] = m
+		}
+	}
+}
+
+// resolveFileRefs replaces the file references in the merged values with the
+// contents of the referenced files and returns the paths of the files read.
+func resolveFileRefs(values map[string]interface{}) ([]string, error) {
+	files := make(map[string]struct{})
+	if err := resolveFileRefsIn(values, files); err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func resolveFileRefsIn(values map[string]interface{}, files map[string]struct{}) error {
+	for key, val := range values {
+		resolved, err := resolveFileRef(val, files)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		values[key] = resolved
+	}
+	return nil
+}
+
+func resolveFileRef(val interface{}, files map[string]struct{}) (interface{}, error) {
+	switch v := val.(type) {
+	case fileRef:
+		files[string(v)] = struct{}{}
+		return readSecretFile(string(v))
+	case []interface{}:
+		for i, item := range v {
+			resolved, err := resolveFileRef(item, files)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = resolved
+		}
+		return v, nil
+	default:
+		if m, ok := toStringMap(val); ok {
+			return m, resolveFileRefsIn(m, files)
+		}
+		return val, nil
+	}
+}
+
+// readSecretFile reads a secret file, dropping the trailing newline
+// most editors and `echo` add.
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading secret file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// Secret is a string config value that is masked whenever it is printed,
+// logged or marshaled, e.g. `Password configo.Secret`. Use Value to get the
+// actual value. Plain fields can be marked secret with the `secret:"true"` tag.
+type Secret = secret.Secret
+
+// Redact returns a copy of cfg with its secret fields (`secret:"true"` tags and
+// Secret fields) masked, for printing it with %+v or logging it.
+func Redact[T any](cfg T) T {
+	return secret.Redact(cfg)
+}
+
+// secretValues returns the values of the secret fields of T in the merged values,
+// so that they can be masked in error messages.
+func secretValues[T any](values map[string]interface{}) []string {
+	var configStruct T
+	var out []string
+	for _, bindKey := range secret.BindKeys(configStruct) {
+		if value, ok := lookupValue(values, bindKey); ok {
+			if str := fmt.Sprint(value); str != "" {
+				out = append(out, str)
+			}
+		}
+	}
+	return out
+}
+
+// maskedError masks secret values in the message of the wrapped error,
+// e.g. in a decoding error quoting the offending value.
+type maskedError struct {
+	err     error
+	secrets []string
+}
+
+func maskSecrets(err error, secrets []string) error {
+	if err == nil || len(secrets) == 0 {
+		return err
+	}
+	return &maskedError{err: err, secrets: secrets}
+}
+
+func (e *maskedError) Error() string {
+	return secret.Mask(e.err.Error(), e.secrets)
+}
+
+func (e *maskedError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,95 @@
+package configo
+
+import (
+	"context"
+	"reflect"
+)
+
+// SelectUpdate is a change of the part of the config chosen by a selector.
+type SelectUpdate[V any] struct {
+	OldValue V
+	NewValue V
+}
+
+// SelectOption configures SubscribeSelect and Watch.
+type SelectOption[V any] func(*selectOptions[V])
+
+type selectOptions[V any] struct {
+	equal func(a, b V) bool
+}
+
+// SelectEqual replaces reflect.DeepEqual as the comparison deciding
+// whether the selected value has changed.
+func SelectEqual[V any](equal func(a, b V) bool) SelectOption[V] {
+	return func(o *selectOptions[V]) {
+		o.equal = equal
+	}
+}
+
+// SubscribeSelect is like ChangeCh, but only delivers when the value chosen by
+// selector has changed, e.g. the database settings:
+//
+//	updates := configo.SubscribeSelect(ctx, cm, func(c AppConfig) DatabaseConfig { return c.Database })
+//
+// Updates that the receiver has not picked up yet are coalesced, so OldValue is
+// always the last value the receiver has seen. The channel is closed once ctx
+// is done or the manager is closed.
+func SubscribeSelect[T, V any](ctx context.Context, cm IConfigManager[T], selector func(T) V, opts ...SelectOption[V]) <-chan SelectUpdate[V] {
+	o := selectOptions[V]{
+		equal: func(a, b V) bool { return reflect.DeepEqual(a, b) },
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	// Subscribe before taking the snapshot, so a change applied in between
+	// is delivered rather than lost.
+	changes := cm.ChangeCh(ctx)
+	last := selector(cm.Config())
+	out := make(chan SelectUpdate[V], 1)
+
+	go func() {
+		defer close(out)
+		for msg := range changes {
+			value := selector(msg.NewConfig)
+			if o.equal(last, value) {
+				continue
+			}
+			update := SelectUpdate[V]{OldValue: last, NewValue: value}
+			last = value
+
+			select {
+			case out <- update:
+				continue
+			default:
+			}
+			// The previous update has not been received yet: merge it into this one.
+			select {
+			case pending := <-out:
+				update.OldValue = pending.OldValue
+			default:
+			}
+			if !o.equal(update.OldValue, update.NewValue) {
+				out <- update
+			}
+		}
+	}()
+
+	return out
+}
+
+// Watch calls handler with the old and new value chosen by selector whenever
+// that value changes (see SubscribeSelect). Handlers run one at a time, in a
+// separate goroutine. Watching stops when stop is called or the manager is closed.
+func Watch[T, V any](cm IConfigManager[T], selector func(T) V, handler func(oldValue, newValue V), opts ...SelectOption[V]) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	updates := SubscribeSelect(ctx, cm, selector, opts...)
+
+	go func() {
+		for update := range updates {
+			handler(update.OldValue, update.NewValue)
+		}
+	}()
+
+	return cancel
+}
@@ -0,0 +1,90 @@
+package configo
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newMemoryManager(t *testing.T, values map[string]interface{}) (*ConfigManager[TestConfig], *MemorySource) {
+	t.Helper()
+	source := NewMemorySource(values)
+	cm, err := NewConfigManager[TestConfig](WithSources[TestConfig](NewDefaultsSource[TestConfig](), source))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	t.Cleanup(func() { cm.Close() })
+	return cm, source
+}
+
+// Проверка подписки на часть конфигурации: изменения других полей не доставляются
+func TestSubscribeSelect(t *testing.T) {
+	cm, source := newMemoryManager(t, map[string]interface{}{"appName": "app", "server.port": 8080})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := SubscribeSelect(ctx, cm, func(c TestConfig) ServerConfig { return c.Server })
+
+	source.Set("appName", "changed")
+	if err := cm.Reload(ctx); err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	source.Set("server.port", 9090)
+	if err := cm.Reload(ctx); err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+
+	select {
+	case update := <-updates:
+		if update.OldValue.Port != 8080 || update.NewValue.Port != 9090 {
+			t.Errorf("Expected the port to change from 8080 to 9090, got %+v", update)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timeout waiting for the server update")
+	}
+
+	select {
+	case update := <-updates:
+		t.Errorf("Expected no further updates, got %+v", update)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	cancel()
+	for range updates {
+	}
+}
+
+// Проверка Watch с пользовательским сравнением
+func TestWatch(t *testing.T) {
+	cm, source := newMemoryManager(t, map[string]interface{}{"appName": "app"})
+
+	changes := make(chan [2]string, 10)
+	stop := Watch(cm, func(c TestConfig) string { return c.AppName }, func(oldValue, newValue string) {
+		changes <- [2]string{oldValue, newValue}
+	}, SelectEqual(strings.EqualFold))
+	defer stop()
+
+	source.Set("appName", "APP")
+	if err := cm.Reload(context.Background()); err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	source.Set("appName", "other")
+	if err := cm.Reload(context.Background()); err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+
+	select {
+	case change := <-changes:
+		if change != [2]string{"app", "other"} {
+			t.Errorf("Expected a change from 'app' to 'other', got %v", change)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timeout waiting for the change")
+	}
+	select {
+	case change := <-changes:
+		t.Errorf("Expected a case-only change to be ignored, got %v", change)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
@@ -0,0 +1,682 @@
+package configo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+	"github.com/vsysa/configo/internal/parser/defaultValues"
+	"github.com/vsysa/configo/internal/parser/dotenv"
+	"github.com/vsysa/configo/internal/parser/env"
+	"github.com/vsysa/configo/internal/parser/flags"
+)
+
+// Source is a single layer of configuration values.
+//
+// ConfigManager loads all of its sources, deep-merges their values in
+// precedence order (later sources override earlier ones) and decodes the
+// result into the config struct.
+type Source interface {
+	// Load returns the values of the source as a nested map keyed by
+	// bind keys, e.g. {"server": {"port": 8080}}.
+	Load(ctx context.Context) (map[string]interface{}, error)
+
+	// Watch returns a channel that receives a value whenever the source
+	// changes, or nil if the source cannot be watched.
+	// The channel is closed once ctx is done.
+	Watch(ctx context.Context) <-chan struct{}
+}
+
+var (
+	_ Source = (*FileSource)(nil)
+	_ Source = (*DirSource)(nil)
+	_ Source = (*EnvSource[any])(nil)
+	_ Source = (*FlagSource[any])(nil)
+	_ Source = (*DefaultsSource[any])(nil)
+	_ Source = (*MemorySource)(nil)
+	_ Source = (*BytesSource)(nil)
+	_ Source = (*ReaderSource)(nil)
+	_ Source = (*FSSource)(nil)
+	_ Source = (*DotenvSource[any])(nil)
+)
+
+// Format is the encoding of a config document.
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+	FormatTOML Format = "toml"
+	// FormatDotenv is a file of KEY=VALUE lines. ConfigManager maps the keys
+	// through the env var names of the config struct (see GenerateEnvHelp).
+	FormatDotenv Format = "dotenv"
+)
+
+// FileSource reads values from a single config file. Unless set with
+// FileFormat, the format is detected from the file extension.
+// Files listed under the `include` key are merged below the file (see IncludeKey).
+type FileSource struct {
+	path   string
+	format Format
+	// optional makes a missing file load as an empty layer instead of failing.
+	optional bool
+
+	includes includeWatch
+}
+
+// FileOption configures a FileSource.
+type FileOption func(*FileSource)
+
+// FileFormat sets the format of the file explicitly, e.g. for a file
+// without an extension.
+func FileFormat(format Format) FileOption {
+	return func(s *FileSource) {
+		s.format = format
+	}
+}
+
+// NewFileSource creates a Source reading the config file at path.
+func NewFileSource(path string, opts ...FileOption) *FileSource {
+	s := &FileSource{path: path}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// NewOptionalFileSource creates a Source reading the config file at path,
+// which loads as an empty layer while the file does not exist.
+// The file is still watched, so it is picked up once it appears.
+func NewOptionalFileSource(path string) *FileSource {
+	return &FileSource{path: path, optional: true}
+}
+
+func (s *FileSource) Load(ctx context.Context) (map[string]interface{}, error) {
+	values, _, err := s.loadLocated(ctx)
+	return values, err
+}
+
+func (s *FileSource) loadLocated(ctx context.Context) (map[string]interface{}, map[string]string, error) {
+	if s.optional {
+		if _, err := os.Stat(s.path); errors.Is(err, fs.ErrNotExist) {
+			return map[string]interface{}{}, nil, nil
+		}
+	}
+	values, locations, included, err := readConfigFileWithIncludes(s.path, s.format)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.includes.track(included)
+	return values, locations, nil
+}
+
+func (s *FileSource) Watch(ctx context.Context) <-chan struct{} {
+	return watchFiles(ctx, s.watch)
+}
+
+func (s *FileSource) watch(w *fileWatcher) error {
+	return errors.Join(w.Add(s.path), s.includes.watch(w))
+}
+
+func (s *FileSource) String() string {
+	return "file " + s.path
+}
+
+// DirSource reads values from all files matching a glob pattern
+// (e.g. "/etc/app/conf.d/*.yml"), merged in lexical order.
+type DirSource struct {
+	pattern string
+
+	includes includeWatch
+}
+
+// NewDirSource creates a Source reading every file that matches the glob pattern.
+func NewDirSource(pattern string) *DirSource {
+	return &DirSource{pattern: pattern}
+}
+
+func (s *DirSource) Load(ctx context.Context) (map[string]interface{}, error) {
+	values, _, err := s.loadLocated(ctx)
+	return values, err
+}
+
+func (s *DirSource) loadLocated(ctx context.Context) (map[string]interface{}, map[string]string, error) {
+	matches, err := filepath.Glob(s.pattern)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid config dir pattern %s: %w", s.pattern, err)
+	}
+	sort.Strings(matches)
+
+	values := make(map[string]interface{})
+	locations := make(map[string]string)
+	var included []string
+	for _, match := range matches {
+		if info, err := os.Stat(match); err != nil || info.IsDir() {
+			continue
+		}
+		fileValues, fileLocations, fileIncluded, err := readConfigFileWithIncludes(match, "")
+		if err != nil {
+			return nil, nil, err
+		}
+		mergeValues(values, fileValues)
+		maps.Copy(locations, fileLocations)
+		included = append(included, fileIncluded...)
+	}
+	s.includes.track(included)
+	return values, locations, nil
+}
+
+func (s *DirSource) Watch(ctx context.Context) <-chan struct{} {
+	return watchFiles(ctx, s.watch)
+}
+
+func (s *DirSource) watch(w *fileWatcher) error {
+	return errors.Join(w.AddPattern(s.pattern), s.includes.watch(w))
+}
+
+func (s *DirSource) String() string {
+	return "dir " + s.pattern
+}
+
+// EnvSource reads values from the environment variables described by the
+// `env` and `mapstructure` tags of T (see GenerateEnvHelp). An unset variable
+// is read from the file named by its _FILE counterpart, if any.
+type EnvSource[T any] struct{}
+
+// NewEnvSource creates a Source reading the environment variables of T.
+func NewEnvSource[T any]() *EnvSource[T] {
+	return &EnvSource[T]{}
+}
+
+func (s *EnvSource[T]) Load(ctx context.Context) (map[string]interface{}, error) {
+	values, _, err := s.loadLocated(ctx)
+	return values, err
+}
+
+func (s *EnvSource[T]) loadLocated(ctx context.Context) (map[string]interface{}, map[string]string, error) {
+	values, locations := envValues[T](os.LookupEnv)
+	return values, locations, nil
+}
+
+// envValues maps the variables returned by lookup to bind keys through the
+// env var names of T. It also returns the variable each bind key was read from.
+func envValues[T any](lookup func(string) (string, bool)) (map[string]interface{}, map[string]string) {
+	var configStruct T
+	values := make(map[string]interface{})
+	locations := make(map[string]string)
+	for _, info := range env.GetEnvs(configStruct) {
+		// Empty env vars are treated as unset, the same way Viper does it.
+		if val, ok := lookup(info.EnvVar); ok && val != "" {
+			setValue(values, info.BindKey, val)
+			locations[strings.ToLower(info.BindKey)] = info.EnvVar
+		} else if ref, ok := lookupEnvFile(lookup, info.EnvVar); ok {
+			setValue(values, info.BindKey, ref)
+			locations[strings.ToLower(info.BindKey)] = info.EnvVar + EnvFileSuffix
+		}
+	}
+	return values, locations
+}
+
+func (s *EnvSource[T]) Watch(ctx context.Context) <-chan struct{} {
+	return nil
+}
+
+func (s *EnvSource[T]) String() string {
+	return "env"
+}
+
+// FlagSource reads values from the command-line flags described by the
+// `flag` and `mapstructure` tags of T (see NewFlagSet and GenerateFlagHelp).
+// Only flags that were set on the command line are taken into account,
+// so unset flags do not hide values from lower layers.
+type FlagSource[T any] struct {
+	flagSet *flag.FlagSet
+}
+
+// NewFlagSource creates a Source reading the flags of T from an already
+// parsed flag set, usually created with NewFlagSet.
+func NewFlagSource[T any](flagSet *flag.FlagSet) *FlagSource[T] {
+	return &FlagSource[T]{flagSet: flagSet}
+}
+
+func (s *FlagSource[T]) Load(ctx context.Context) (map[string]interface{}, error) {
+	values, _, err := s.loadLocated(ctx)
+	return values, err
+}
+
+func (s *FlagSource[T]) loadLocated(ctx context.Context) (map[string]interface{}, map[string]string, error) {
+	var configStruct T
+	bindKeys := make(map[string]string)
+	for _, info := range flags.GetFlags(configStruct) {
+		bindKeys[info.Name] = info.BindKey
+	}
+
+	values := make(map[string]interface{})
+	locations := make(map[string]string)
+	s.flagSet.Visit(func(f *flag.Flag) {
+		if bindKey, ok := bindKeys[f.Name]; ok {
+			setValue(values, bindKey, f.Value.String())
+			locations[strings.ToLower(bindKey)] = "-" + f.Name
+		}
+	})
+	return values, locations, nil
+}
+
+func (s *FlagSource[T]) Watch(ctx context.Context) <-chan struct{} {
+	return nil
+}
+
+func (s *FlagSource[T]) String() string {
+	return "flags"
+}
+
+// NewFlagSet creates a flag set with one flag per field of T. Flag names are
+// the bind keys (e.g. --server.port) unless renamed with a `flag:"..."` tag;
+// `flag:"-"` skips a field. The `default` and `help` tags show up in the usage.
+//
+// Further flags may be added to the set before parsing it and passing it
+// to WithFlagSet.
+func NewFlagSet[T any](name string, errorHandling flag.ErrorHandling) *flag.FlagSet {
+	var configStruct T
+	return flags.NewFlagSet(configStruct, name, errorHandling)
+}
+
+// DefaultsSource provides the values of the `default` tags of T.
+type DefaultsSource[T any] struct {
+	profile string
+}
+
+// NewDefaultsSource creates a Source providing the `default` tag values of T.
+func NewDefaultsSource[T any]() *DefaultsSource[T] {
+	return &DefaultsSource[T]{}
+}
+
+func (s *DefaultsSource[T]) Load(ctx context.Context) (map[string]interface{}, error) {
+	values, _, err := s.loadLocated(ctx)
+	return values, err
+}
+
+func (s *DefaultsSource[T]) loadLocated(ctx context.Context) (map[string]interface{}, map[string]string, error) {
+	var configStruct T
+	defaults, err := defaultValues.GetProfileDefaultValues(configStruct, s.profile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ConfigParsingError, err)
+	}
+	// Profile defaults are told apart from the base ones they override.
+	base := make(map[string]interface{})
+	if s.profile != "" {
+		baseDefaults, err := defaultValues.GetDefaultValues(configStruct)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %w", ConfigParsingError, err)
+		}
+		for _, v := range baseDefaults {
+			base[v.BindKey] = v.DefaultValue
+		}
+	}
+
+	values := make(map[string]interface{})
+	locations := make(map[string]string)
+	for _, v := range defaults {
+		setValue(values, v.BindKey, v.DefaultValue)
+		location := "default tag"
+		if baseValue, ok := base[v.BindKey]; s.profile != "" && (!ok || !reflect.DeepEqual(baseValue, v.DefaultValue)) {
+			location = "default_" + s.profile + " tag"
+		}
+		locations[strings.ToLower(v.BindKey)] = location
+	}
+	return values, locations, nil
+}
+
+func (s *DefaultsSource[T]) Watch(ctx context.Context) <-chan struct{} {
+	return nil
+}
+
+func (s *DefaultsSource[T]) String() string {
+	return "defaults"
+}
+
+// MemorySource holds values in memory. Values can be changed with Set,
+// which notifies the watchers of the source.
+type MemorySource struct {
+	mu       sync.RWMutex
+	values   map[string]interface{}
+	watchers map[chan struct{}]struct{}
+}
+
+// NewMemorySource creates a Source holding the given values. Keys may be
+// nested maps or dotted bind keys, e.g. {"server.port": 8080}.
+func NewMemorySource(values map[string]interface{}) *MemorySource {
+	return &MemorySource{
+		values:   copyValues(values),
+		watchers: make(map[chan struct{}]struct{}),
+	}
+}
+
+// Set stores value under the given bind key and notifies the watchers.
+func (s *MemorySource) Set(bindKey string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	setValue(s.values, bindKey, value)
+	for ch := range s.watchers {
+		select {
+		case ch <- struct{}{}:
+		default: // a change is already pending
+		}
+	}
+}
+
+func (s *MemorySource) Load(ctx context.Context) (map[string]interface{}, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return copyValues(s.values), nil
+}
+
+func (s *MemorySource) Watch(ctx context.Context) <-chan struct{} {
+	ch := make(chan struct{}, 1)
+	s.mu.Lock()
+	s.watchers[ch] = struct{}{}
+	s.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		s.mu.Lock()
+		delete(s.watchers, ch)
+		close(ch)
+		s.mu.Unlock()
+	}()
+
+	return ch
+}
+
+func (s *MemorySource) String() string {
+	return "memory"
+}
+
+// BytesSource decodes an in-memory config document, e.g. one embedded
+// with //go:embed. It never changes, so it is not watched.
+type BytesSource struct {
+	format Format
+	data   []byte
+}
+
+// NewBytesSource creates a Source decoding data in the given format.
+func NewBytesSource(format Format, data []byte) *BytesSource {
+	return &BytesSource{format: format, data: data}
+}
+
+func (s *BytesSource) Load(ctx context.Context) (map[string]interface{}, error) {
+	return decodeConfig(s.format, s.data)
+}
+
+func (s *BytesSource) documentFormat() Format {
+	return s.format
+}
+
+func (s *BytesSource) read() ([]byte, error) {
+	return s.data, nil
+}
+
+func (s *BytesSource) Watch(ctx context.Context) <-chan struct{} {
+	return nil
+}
+
+func (s *BytesSource) String() string {
+	return fmt.Sprintf("%s document", s.format)
+}
+
+// ReaderSource decodes a config document read from an io.Reader.
+// The reader is consumed on the first Load and the document is kept,
+// so it can be decoded again on reloads. It is not watched.
+type ReaderSource struct {
+	format Format
+	r      io.Reader
+
+	once sync.Once
+	data []byte
+	err  error
+}
+
+// NewReaderSource creates a Source decoding the document read from r in the given format.
+func NewReaderSource(format Format, r io.Reader) *ReaderSource {
+	return &ReaderSource{format: format, r: r}
+}
+
+func (s *ReaderSource) Load(ctx context.Context) (map[string]interface{}, error) {
+	data, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	return decodeConfig(s.format, data)
+}
+
+func (s *ReaderSource) documentFormat() Format {
+	return s.format
+}
+
+func (s *ReaderSource) read() ([]byte, error) {
+	s.once.Do(func() {
+		s.data, s.err = io.ReadAll(s.r)
+	})
+	if s.err != nil {
+		return nil, fmt.Errorf("error reading config: %w", s.err)
+	}
+	return s.data, nil
+}
+
+func (s *ReaderSource) Watch(ctx context.Context) <-chan struct{} {
+	return nil
+}
+
+func (s *ReaderSource) String() string {
+	return fmt.Sprintf("%s reader", s.format)
+}
+
+// FSSource reads a config file from a fs.FS, e.g. an embed.FS.
+// The format is detected from the file extension. It is not watched.
+type FSSource struct {
+	fsys fs.FS
+	path string
+}
+
+// NewFSSource creates a Source reading the config file at path in fsys.
+func NewFSSource(fsys fs.FS, path string) *FSSource {
+	return &FSSource{fsys: fsys, path: path}
+}
+
+func (s *FSSource) Load(ctx context.Context) (map[string]interface{}, error) {
+	data, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	values, err := decodeConfig(s.documentFormat(), data)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file %s: %w", s.path, err)
+	}
+	return values, nil
+}
+
+func (s *FSSource) documentFormat() Format {
+	return formatFromPath(s.path)
+}
+
+func (s *FSSource) read() ([]byte, error) {
+	data, err := fs.ReadFile(s.fsys, s.path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file %s: %w", s.path, err)
+	}
+	return data, nil
+}
+
+func (s *FSSource) Watch(ctx context.Context) <-chan struct{} {
+	return nil
+}
+
+func (s *FSSource) String() string {
+	return "fs file " + s.path
+}
+
+// DotenvSource reads a dotenv file (KEY=VALUE lines) and maps its keys
+// through the env var names of T, the same way EnvSource maps the environment.
+type DotenvSource[T any] struct {
+	path string
+	// optional makes a missing file load as an empty layer instead of failing.
+	optional bool
+}
+
+// NewDotenvSource creates a Source reading the dotenv file at path.
+func NewDotenvSource[T any](path string) *DotenvSource[T] {
+	return &DotenvSource[T]{path: path}
+}
+
+func (s *DotenvSource[T]) Load(ctx context.Context) (map[string]interface{}, error) {
+	values, _, err := s.loadLocated(ctx)
+	return values, err
+}
+
+func (s *DotenvSource[T]) loadLocated(ctx context.Context) (map[string]interface{}, map[string]string, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if s.optional && errors.Is(err, fs.ErrNotExist) {
+			return map[string]interface{}{}, nil, nil
+		}
+		return nil, nil, fmt.Errorf("error reading dotenv file %s: %w", s.path, err)
+	}
+	vars, err := dotenv.Parse(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing dotenv file %s: %w", s.path, err)
+	}
+	values, locations := envValues[T](lookupVar(vars))
+	for bindKey, envVar := range locations {
+		locations[bindKey] = s.path + ": " + envVar
+	}
+	return values, locations, nil
+}
+
+// lookupVar adapts a map of variables to the signature of os.LookupEnv.
+func lookupVar(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		val, ok := vars[name]
+		return val, ok
+	}
+}
+
+func (s *DotenvSource[T]) Watch(ctx context.Context) <-chan struct{} {
+	return watchFiles(ctx, s.watch)
+}
+
+func (s *DotenvSource[T]) watch(w *fileWatcher) error {
+	return w.Add(s.path)
+}
+
+func (s *DotenvSource[T]) String() string {
+	return "dotenv " + s.path
+}
+
+// rawDocument is implemented by the sources of in-memory and fs.FS documents.
+type rawDocument interface {
+	Source
+	documentFormat() Format
+	read() ([]byte, error)
+}
+
+// newDocument returns the source of a config document passed to the manager.
+// Dotenv documents are mapped through the env var names of T by a dotenvDocument.
+func newDocument[T any](doc rawDocument) Source {
+	if doc.documentFormat() == FormatDotenv {
+		return &dotenvDocument[T]{document: doc}
+	}
+	return doc
+}
+
+// dotenvDocument maps a dotenv document that is not a file on disk through
+// the env var names of T, the same way DotenvSource maps a dotenv file.
+type dotenvDocument[T any] struct {
+	document rawDocument
+}
+
+func (s *dotenvDocument[T]) Load(ctx context.Context) (map[string]interface{}, error) {
+	data, err := s.document.read()
+	if err != nil {
+		return nil, err
+	}
+	vars, err := dotenv.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", sourceName(s.document), err)
+	}
+	values, _ := envValues[T](lookupVar(vars))
+	return values, nil
+}
+
+func (s *dotenvDocument[T]) Watch(ctx context.Context) <-chan struct{} {
+	return nil
+}
+
+func (s *dotenvDocument[T]) String() string {
+	return sourceName(s.document)
+}
+
+// readConfigFile reads a config file with Viper. If format is empty,
+// it is detected from the file extension.
+func readConfigFile(path string, format Format) (map[string]interface{}, error) {
+	values, _, err := readLocatedConfigFile(path, format)
+	return values, err
+}
+
+// readLocatedConfigFile reads a config file like readConfigFile and also
+// returns the location of each of its keys (see fileLocations).
+func readLocatedConfigFile(path string, format Format) (map[string]interface{}, map[string]string, error) {
+	if format == "" {
+		format = formatFromPath(path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading config file %s: %w", path, err)
+	}
+	values, err := decodeConfig(format, data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading config file %s: %w", path, err)
+	}
+	return values, fileLocations(path, format, data, values), nil
+}
+
+// decodeConfig decodes a config document in the given format with Viper.
+// Dotenv documents are rejected: their keys are env var names, which only
+// DotenvSource and the document options of ConfigManager can map to bind keys.
+func decodeConfig(format Format, data []byte) (map[string]interface{}, error) {
+	if format == FormatDotenv {
+		return nil, errors.New("dotenv documents must be loaded with NewDotenvSource, WithDotenvFiles or as config files of the manager")
+	}
+	v := viper.New()
+	v.SetConfigType(string(format))
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("error decoding %s config: %w", format, err)
+	}
+	return v.AllSettings(), nil
+}
+
+// formatFromPath detects the format of a config file from its extension.
+func formatFromPath(path string) Format {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	switch ext {
+	case "yml":
+		return FormatYAML
+	case "env":
+		return FormatDotenv
+	default:
+		return Format(ext)
+	}
+}
@@ -0,0 +1,264 @@
+package configo
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"testing/iotest"
+	"time"
+)
+
+// customSource - пример пользовательского источника конфигурации
+type customSource struct {
+	values map[string]interface{}
+}
+
+func (s customSource) Load(ctx context.Context) (map[string]interface{}, error) {
+	return s.values, nil
+}
+
+func (s customSource) Watch(ctx context.Context) <-chan struct{} {
+	return nil
+}
+
+// Проверка порядка приоритета источников: последующие переопределяют предыдущие
+func TestConfigManager_WithSources(t *testing.T) {
+	configPath := createTempYAMLConfig(t, "appName: \"file\"\nserver:\n  host: \"filehost\"\n  port: 8080\n")
+	defer os.Remove(configPath)
+
+	setEnv(t, "SERVER_PORT", "9090")
+	defer unsetEnv(t, "SERVER_PORT")
+
+	cm, err := NewConfigManager[TestConfig](WithSources[TestConfig](
+		NewDefaultsSource[TestConfig](),
+		NewFileSource(configPath),
+		customSource{values: map[string]interface{}{"appName": "custom"}},
+		NewEnvSource[TestConfig](),
+		NewMemorySource(map[string]interface{}{"server.host": "memoryhost"}),
+	))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+
+	config := cm.Config()
+	if config.AppName != "custom" {
+		t.Errorf("Expected AppName to be 'custom', got '%s'", config.AppName)
+	}
+	if config.Server.Host != "memoryhost" {
+		t.Errorf("Expected Server.Host to be 'memoryhost', got '%s'", config.Server.Host)
+	}
+	if config.Server.Port != 9090 {
+		t.Errorf("Expected Server.Port to be 9090, got %d", config.Server.Port)
+	}
+	// Значение по умолчанию из тега default
+	if !config.Enable {
+		t.Errorf("Expected Enable to be true, got %v", config.Enable)
+	}
+}
+
+// Проверка перезагрузки при изменении источника в памяти
+func TestConfigManager_MemorySourceReload(t *testing.T) {
+	memory := NewMemorySource(map[string]interface{}{
+		"appName": "initial",
+		"server":  map[string]interface{}{"port": 8080},
+	})
+
+	cm, err := NewConfigManager[TestConfig](WithSources[TestConfig](memory))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := cm.ChangeCh(ctx)
+
+	memory.Set("server.port", 9090)
+
+	update := waitForUpdate(t, updates, func(c TestConfig) bool { return c.Server.Port == 9090 })
+	if update.OldConfig.Server.Port != 8080 {
+		t.Errorf("Expected OldConfig.Server.Port to be 8080, got %d", update.OldConfig.Server.Port)
+	}
+	if update.NewConfig.AppName != "initial" {
+		t.Errorf("Expected NewConfig.AppName to be 'initial', got '%s'", update.NewConfig.AppName)
+	}
+}
+
+// neverClosedSource - источник, который никогда не закрывает канал Watch
+type neverClosedSource struct {
+	customSource
+	changes chan struct{}
+}
+
+func (s neverClosedSource) Watch(ctx context.Context) <-chan struct{} {
+	return s.changes
+}
+
+// Close не должен зависеть от того, закрывает ли источник канал Watch
+func TestConfigManager_CloseWithUnclosedWatch(t *testing.T) {
+	cm, err := NewConfigManager[TestConfig](WithSources[TestConfig](
+		neverClosedSource{customSource: customSource{values: map[string]interface{}{"appName": "custom"}}, changes: make(chan struct{})},
+	))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		cm.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatalf("Close did not return while the source's Watch channel is open")
+	}
+}
+
+// Наблюдатель файлов создаётся только когда появляются файлы для отслеживания
+func TestConfigManager_LazyFileWatcher(t *testing.T) {
+	cm, err := NewConfigManager[TestConfig](WithSources[TestConfig](
+		NewMemorySource(map[string]interface{}{"appName": "memory"}),
+		NewEnvSource[TestConfig](),
+	))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer cm.Close()
+
+	hasWatcher := func() bool {
+		cm.watcherMu.Lock()
+		defer cm.watcherMu.Unlock()
+		return cm.watcher != nil
+	}
+	if hasWatcher() {
+		t.Errorf("Expected no file watcher without files to watch")
+	}
+
+	passwordPath := filepath.Join(t.TempDir(), "db_password")
+	writeFile(t, passwordPath, "s3cret\n")
+	setEnv(t, "DB_PASSWORD_FILE", passwordPath)
+	defer unsetEnv(t, "DB_PASSWORD_FILE")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := cm.ChangeCh(ctx)
+
+	if err := cm.Reload(ctx); err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	if !hasWatcher() {
+		t.Fatalf("Expected a file watcher for the secret file")
+	}
+
+	writeFile(t, passwordPath, "rotated\n")
+	waitForUpdate(t, updates, func(c TestConfig) bool { return c.Database.Password == "rotated" })
+}
+
+func TestMergeValues(t *testing.T) {
+	dst := map[string]interface{}{
+		"server": map[string]interface{}{"host": "a", "port": 1},
+		"name":   "a",
+	}
+	mergeValues(dst, map[string]interface{}{
+		"Server": map[string]interface{}{"Port": 2},
+		"name":   nil,
+	})
+
+	server := dst["server"].(map[string]interface{})
+	if server["host"] != "a" || server["port"] != 2 {
+		t.Errorf("Unexpected server values after merge: %v", server)
+	}
+	if dst["name"] != "a" {
+		t.Errorf("Expected nil value to be skipped, got %v", dst["name"])
+	}
+}
+
+// Проверка загрузки конфигурации из io.Reader, []byte и fs.FS
+func TestConfigManager_NonFileDocuments(t *testing.T) {
+	setEnv(t, "SERVER_PORT", "9090")
+	defer unsetEnv(t, "SERVER_PORT")
+
+	fsys := fstest.MapFS{
+		"config/app.json": &fstest.MapFile{Data: []byte(`{"appName": "fs", "server": {"host": "fshost"}}`)},
+		"config/app.env":  &fstest.MapFile{Data: []byte("APP=fsdotenv\n")},
+	}
+
+	tests := []struct {
+		name   string
+		option Option[TestConfig]
+		app    string
+	}{
+		{
+			name:   "reader",
+			option: WithReader[TestConfig](FormatYAML, strings.NewReader("appName: \"reader\"\n")),
+			app:    "reader",
+		},
+		{
+			name:   "bytes",
+			option: WithBytes[TestConfig](FormatTOML, []byte("appName = \"bytes\"\n")),
+			app:    "bytes",
+		},
+		{
+			name:   "fs",
+			option: WithFS[TestConfig](fsys, "config/app.json"),
+			app:    "fs",
+		},
+		// Ключи dotenv сопоставляются с именами переменных окружения
+		{
+			name:   "dotenv bytes",
+			option: WithBytes[TestConfig](FormatDotenv, []byte("APP=dotenv\nSERVER_PORT=1\n")),
+			app:    "dotenv",
+		},
+		{
+			name:   "dotenv fs",
+			option: WithFS[TestConfig](fsys, "config/app.env"),
+			app:    "fsdotenv",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm, err := NewConfigManager[TestConfig](tt.option)
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+			defer cm.Close()
+
+			config := cm.Config()
+			if config.AppName != tt.app {
+				t.Errorf("Expected AppName to be '%s', got '%s'", tt.app, config.AppName)
+			}
+			// Переменные окружения и значения по умолчанию применяются как обычно
+			if config.Server.Port != 9090 {
+				t.Errorf("Expected Server.Port to be 9090, got %d", config.Server.Port)
+			}
+			if !config.Enable {
+				t.Errorf("Expected Enable to be true, got %v", config.Enable)
+			}
+		})
+	}
+}
+
+func TestConfigManager_ReaderError(t *testing.T) {
+	_, err := NewConfigManager[TestConfig](WithReader[TestConfig](FormatYAML, iotest.ErrReader(errors.New("broken"))))
+	if err == nil {
+		t.Errorf("Expected error for failing reader")
+	}
+}
+
+// Фрагменты dotenv в каталоге нельзя сопоставить с переменными окружения
+func TestDirSource_DotenvFragment(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "10-app.env"), "APP=dir\n")
+
+	_, err := NewDirSource(filepath.Join(dir, "*")).Load(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "dotenv") {
+		t.Errorf("Expected an error for a dotenv fragment, got %v", err)
+	}
+}
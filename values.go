@@ -0,0 +1,125 @@
+package configo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// setValue stores value in the nested values map under the given bind key,
+// e.g. "server.port" -> {"server": {"port": value}}.
+// Keys are lowercased, since Viper treats them case-insensitively.
+func setValue(values map[string]interface{}, bindKey string, value interface{}) {
+	path := strings.Split(strings.ToLower(bindKey), ".")
+	m := values
+	for _, key := range path[:len(path)-1] {
+		next, ok := m[key].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			m[key] = next
+		}
+		m = next
+	}
+	m[path[len(path)-1]] = value
+}
+
+// mergeValues deep-merges src into dst: nested maps are merged recursively,
+// any other value in src replaces the one in dst. Nil values in src are skipped,
+// so that an empty key in a file does not hide a value from a lower layer.
+func mergeValues(dst, src map[string]interface{}) {
+	for key, srcVal := range src {
+		key = strings.ToLower(key)
+		if srcVal == nil {
+			continue
+		}
+		srcMap, srcIsMap := toStringMap(srcVal)
+		if !srcIsMap {
+			if items, ok := srcVal.([]interface{}); ok {
+				// Copied, so that resolving file references in the
+				// merged values does not modify the values of the source.
+				srcVal = append([]interface{}(nil), items...)
+			}
+			dst[key] = srcVal
+			continue
+		}
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if !dstIsMap {
+			dstMap = make(map[string]interface{})
+			dst[key] = dstMap
+		}
+		mergeValues(dstMap, srcMap)
+	}
+}
+
+// copyValues returns a deep copy of the values map. Dotted keys are expanded,
+// so {"server.port": 8080} becomes {"server": {"port": 8080}}.
+func copyValues(values map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(values))
+	for key, val := range values {
+		if m, ok := toStringMap(val); ok {
+			val = copyValues(m)
+		}
+		if strings.Contains(key, ".") {
+			nested := make(map[string]interface{})
+			setValue(nested, key, val)
+			mergeValues(out, nested)
+			continue
+		}
+		mergeValues(out, map[string]interface{}{key: val})
+	}
+	return out
+}
+
+// toStringMap converts the map types produced by the various decoders
+// to map[string]interface{}.
+func toStringMap(val interface{}) (map[string]interface{}, bool) {
+	switch m := val.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			out[fmt.Sprint(k)] = v
+		}
+		return out, true
+	default:
+		return nil, false
+	}
+}
+
+// lookupValue finds the value of a bind key in a nested values map.
+// Keys are compared case-insensitively, the same way Viper does it.
+func lookupValue(values map[string]interface{}, bindKey string) (interface{}, bool) {
+	var current interface{} = values
+	for _, key := range strings.Split(strings.ToLower(bindKey), ".") {
+		m, ok := toStringMap(current)
+		if !ok {
+			return nil, false
+		}
+		found := false
+		for k, v := range m {
+			if strings.ToLower(k) == key {
+				current, found = v, true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return current, current != nil
+}
+
+// valuesChecksum returns the hex-encoded SHA-256 of the merged values.
+// Map keys are sorted by the encoding, so equal values give equal checksums.
+func valuesChecksum(values map[string]interface{}) string {
+	data, err := json.Marshal(values)
+	if err != nil {
+		// Values JSON cannot encode are hashed in their printed form.
+		data = []byte(fmt.Sprintf("%v", values))
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,175 @@
+package configo
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// fileWatcher watches a set of files for changes.
+//
+// Files are watched through their parent directories rather than directly,
+// so that atomic saves (write to a temp file + rename) and symlink swaps are
+// picked up the same way Viper's WatchConfig does it.
+type fileWatcher struct {
+	watcher *fsnotify.Watcher
+	changes chan struct{}
+
+	mu sync.Mutex
+	// files maps a cleaned file path to its last known resolved path.
+	files map[string]string
+	// patterns are glob patterns of files that trigger a reload when they
+	// are created, written, removed or renamed.
+	patterns []string
+	dirs     map[string]struct{}
+
+	// errorHandler receives the errors of the underlying watcher and of
+	// files added after setup, e.g. newly included files. Nil ignores them.
+	errorHandler func(error)
+}
+
+func newFileWatcher() (*fileWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("error creating file watcher: %w", err)
+	}
+	return &fileWatcher{
+		watcher: watcher,
+		changes: make(chan struct{}, 1),
+		files:   make(map[string]string),
+		dirs:    make(map[string]struct{}),
+	}, nil
+}
+
+// watchFiles creates a fileWatcher, lets setup register the files to watch and
+// runs the watcher until ctx is done. It returns nil if the watcher cannot be set up.
+//
+// It backs the Watch methods of the file sources. ConfigManager does not call
+// them, but watches all of its file sources with a single shared fileWatcher.
+func watchFiles(ctx context.Context, setup func(w *fileWatcher) error) <-chan struct{} {
+	w, err := newFileWatcher()
+	if err != nil {
+		return nil
+	}
+	if err := setup(w); err != nil {
+		w.watcher.Close()
+		return nil
+	}
+
+	go w.Run(ctx)
+	return w.changes
+}
+
+// Add starts watching the given files.
+func (w *fileWatcher) Add(paths ...string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, path := range paths {
+		file := filepath.Clean(path)
+		if _, ok := w.files[file]; ok {
+			continue
+		}
+		realFile, _ := filepath.EvalSymlinks(file)
+		w.files[file] = realFile
+
+		if err := w.addDir(filepath.Dir(file)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddPattern starts watching the directory of the given glob pattern,
+// so that matching files being added, removed or edited are noticed.
+func (w *fileWatcher) AddPattern(pattern string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	pattern = filepath.Clean(pattern)
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid config dir pattern %s: %w", pattern, err)
+	}
+	w.patterns = append(w.patterns, pattern)
+
+	return w.addDir(filepath.Dir(pattern))
+}
+
+func (w *fileWatcher) addDir(dir string) error {
+	if _, ok := w.dirs[dir]; ok {
+		return nil
+	}
+	if err := w.watcher.Add(dir); err != nil {
+		return fmt.Errorf("error watching directory %s: %w", dir, err)
+	}
+	w.dirs[dir] = struct{}{}
+	return nil
+}
+
+// Run processes file system events until ctx is done, then closes
+// the underlying watcher and the changes channel.
+func (w *fileWatcher) Run(ctx context.Context) {
+	defer close(w.changes)
+	defer w.watcher.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if w.isRelevant(event) {
+				select {
+				case w.changes <- struct{}{}:
+				default: // a change is already pending
+				}
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			w.reportError(fmt.Errorf("error watching config files: %w", err))
+		}
+	}
+}
+
+// reportError passes err to the error handler, if any.
+func (w *fileWatcher) reportError(err error) {
+	if w.errorHandler != nil {
+		w.errorHandler(err)
+	}
+}
+
+// isRelevant reports whether the event creates, changes, removes or renames
+// one of the watched files, re-points the symlink a watched file resolves
+// through, or does the same to a file matching one of the watched patterns.
+func (w *fileWatcher) isRelevant(event fsnotify.Event) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	name := filepath.Clean(event.Name)
+	relevant := false
+	for file, realFile := range w.files {
+		currentFile, _ := filepath.EvalSymlinks(file)
+		if currentFile != "" && currentFile != realFile {
+			// e.g. the k8s ConfigMap ..data symlink has been swapped
+			w.files[file] = currentFile
+			relevant = true
+			continue
+		}
+		if name == file && event.Op != fsnotify.Chmod {
+			relevant = true
+		}
+	}
+	for _, pattern := range w.patterns {
+		if matched, _ := filepath.Match(pattern, name); matched && event.Op != fsnotify.Chmod {
+			relevant = true
+		}
+	}
+	return relevant
+}